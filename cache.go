@@ -0,0 +1,121 @@
+package cc
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultPatternCacheSize is the default capacity of the compiled pattern cache.
+const DefaultPatternCacheSize = 256
+
+// patterns caches the compiled Patterns, which hold the compiled regular
+// expressions and conditions, so that the And/AndOr families do not compile
+// the same pattern again and again.
+var patterns = newPatternCache(DefaultPatternCacheSize)
+
+// PatternCacheStats represents the statistics of the compiled pattern cache.
+type PatternCacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+	Capacity  int
+}
+
+// GetPatternCacheStats returns the statistics of the compiled pattern cache.
+func GetPatternCacheStats() PatternCacheStats {
+	return patterns.stats()
+}
+
+// SetPatternCacheSize resets the capacity of the compiled pattern cache,
+// the least recently used patterns are evicted if necessary, size less than
+// 1 is treated as 1.
+func SetPatternCacheSize(size int) {
+	patterns.resize(size)
+}
+
+type patternEntry struct {
+	key string
+	p   *Pattern
+}
+
+// patternCache is a concurrency-safe LRU cache, the invalid patterns
+// are cached as well.
+type patternCache struct {
+	mu        sync.Mutex
+	capacity  int
+	ll        *list.List
+	items     map[string]*list.Element
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+func newPatternCache(capacity int) *patternCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &patternCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element, capacity),
+	}
+}
+
+func (c *patternCache) get(pattern string) *Pattern {
+	c.mu.Lock()
+	if e, ok := c.items[pattern]; ok {
+		c.ll.MoveToFront(e)
+		c.hits++
+		p := e.Value.(*patternEntry).p
+		c.mu.Unlock()
+		return p
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	// Compiles without holding the lock, the same pattern may be compiled
+	// concurrently, it doesn't matter.
+	p := newPattern(pattern)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[pattern]; ok {
+		c.ll.MoveToFront(e)
+		return e.Value.(*patternEntry).p
+	}
+	c.items[pattern] = c.ll.PushFront(&patternEntry{key: pattern, p: p})
+	c.evict()
+	return p
+}
+
+func (c *patternCache) evict() {
+	for c.ll.Len() > c.capacity {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*patternEntry).key)
+		c.evictions++
+	}
+}
+
+func (c *patternCache) resize(capacity int) {
+	if capacity < 1 {
+		capacity = 1
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.capacity = capacity
+	c.evict()
+}
+
+func (c *patternCache) stats() PatternCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return PatternCacheStats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Size:      c.ll.Len(),
+		Capacity:  c.capacity,
+	}
+}
@@ -0,0 +1,130 @@
+package cc
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestPatternCacheLRU(t *testing.T) {
+	c := newPatternCache(2)
+	p := c.get("N>1")
+	c.get("^a")
+	assert.Check(t, c.get("N>1"), p)
+	c.get("N>2") // evicts "^a"
+
+	stats := c.stats()
+	assert.Check(t, stats.Hits, uint64(1))
+	assert.Check(t, stats.Misses, uint64(3))
+	assert.Check(t, stats.Evictions, uint64(1))
+	assert.Check(t, stats.Size, 2)
+	_, in := c.items["^a"]
+	assert.Check(t, in, false)
+	_, in = c.items["N>1"]
+	assert.Check(t, in, true)
+
+	c.resize(1)
+	stats = c.stats()
+	assert.Check(t, stats.Size, 1)
+	assert.Check(t, stats.Capacity, 1)
+	_, in = c.items["N>2"]
+	assert.Check(t, in, true)
+}
+
+func TestPatternCacheInvalid(t *testing.T) {
+	c := newPatternCache(2)
+	p := c.get("N>0.0.2")
+	if p.Err() == nil {
+		t.Fatal("expect error, got nothing")
+	}
+	assert.Check(t, c.get("N>0.0.2"), p)
+	assert.Check(t, c.stats().Hits, uint64(1))
+}
+
+func TestPatternCacheConcurrency(t *testing.T) {
+	c := newPatternCache(8)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				p := c.get(fmt.Sprintf("^%d", j%16))
+				assert.Check(t, p.ValidateString(fmt.Sprintf("%d", j%16)), true)
+				p = c.get(fmt.Sprintf("N==%d", j%16))
+				assert.Check(t, p.ValidateInt(j%16), true)
+			}
+		}(i)
+	}
+	wg.Wait()
+	stats := c.stats()
+	assert.Check(t, stats.Size, 8)
+	assert.Check(t, stats.Hits+stats.Misses, uint64(8*100*2))
+}
+
+func TestPatternCacheNoAllocs(t *testing.T) {
+	c := NewConfig()
+	c.Set("int", 40)
+	c.Set("float", 0.5)
+	c.Set("string", "cc")
+	v := NewValue(40)
+
+	cases := map[string]func(){
+		"Config.IntAnd": func() {
+			if _, ok := c.IntAnd("int", "N>=30&&N<=80"); !ok {
+				t.Fatal("expect valid")
+			}
+		},
+		"Config.FloatAndOr": func() {
+			c.FloatAndOr("float", "N in (0, 1]", 0.1)
+		},
+		"Config.StringAnd": func() {
+			if _, ok := c.StringAnd("string", "^c+$"); !ok {
+				t.Fatal("expect valid")
+			}
+		},
+		"Value.IntAnd": func() {
+			if _, ok := v.IntAnd("N%2==0"); !ok {
+				t.Fatal("expect valid")
+			}
+		},
+	}
+	for name, fn := range cases {
+		fn() // warm up the cache
+		if n := testing.AllocsPerRun(100, fn); n != 0 {
+			t.Fatalf("%s: expect no allocation, got %v", name, n)
+		}
+	}
+}
+
+func BenchmarkConfigIntAnd(b *testing.B) {
+	c := NewConfig()
+	c.Set("int", 40)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.IntAnd("int", "N>=30&&N<=80")
+	}
+}
+
+func BenchmarkConfigFloatAnd(b *testing.B) {
+	c := NewConfig()
+	c.Set("float", 0.5)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.FloatAnd("float", "N in (0, 1]")
+	}
+}
+
+func BenchmarkConfigStringAnd(b *testing.B) {
+	c := NewConfig()
+	c.Set("string", "cc")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.StringAnd("string", "^c+$")
+	}
+}
@@ -1,6 +1,13 @@
 package cc
 
-import "time"
+import (
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"time"
+)
 
 // Configer is a abstraction for config.
 type Configer interface {
@@ -34,20 +41,110 @@ type Configer interface {
 	Int64And(name string, pattern string) (int64, bool)
 	Int64AndOr(name string, pattern string, deflt int64) int64
 
+	Uint(name string) uint
+	UintOr(name string, deflt uint) uint
+	UintE(name string) (uint, error)
+	UintAnd(name string, pattern string) (uint, bool)
+	UintAndOr(name string, pattern string, deflt uint) uint
+
+	Uint64(name string) uint64
+	Uint64Or(name string, deflt uint64) uint64
+	Uint64E(name string) (uint64, error)
+	Uint64And(name string, pattern string) (uint64, bool)
+	Uint64AndOr(name string, pattern string, deflt uint64) uint64
+
+	Int32(name string) int32
+	Int32Or(name string, deflt int32) int32
+	Int32E(name string) (int32, error)
+	Int32And(name string, pattern string) (int32, bool)
+	Int32AndOr(name string, pattern string, deflt int32) int32
+
 	Float(name string) float64
 	FloatOr(name string, deflt float64) float64
 	FloatAnd(name string, pattern string) (float64, bool)
 	FloatAndOr(name string, pattern string, deflt float64) float64
 
+	Float32(name string) float32
+	Float32Or(name string, deflt float32) float32
+	Float32E(name string) (float32, error)
+	Float32And(name string, pattern string) (float32, bool)
+	Float32AndOr(name string, pattern string, deflt float32) float32
+
 	Duration(name string) time.Duration
 	DurationOr(name string, deflt int64) time.Duration
 	DurationAnd(name string, pattern string) (time.Duration, bool)
 	DurationAndOr(name string, pattern string, deflt int64) time.Duration
+
+	StringSlice(name string) []string
+	StringSliceOr(name string, deflt []string) []string
+	StringSliceAnd(name string, pattern string) ([]string, bool)
+	StringSliceAndOr(name string, pattern string, deflt []string) []string
+
+	IntSlice(name string) []int
+	IntSliceOr(name string, deflt []int) []int
+	IntSliceAnd(name string, pattern string) ([]int, bool)
+	IntSliceAndOr(name string, pattern string, deflt []int) []int
+
+	FloatSlice(name string) []float64
+	FloatSliceOr(name string, deflt []float64) []float64
+	FloatSliceAnd(name string, pattern string) ([]float64, bool)
+	FloatSliceAndOr(name string, pattern string, deflt []float64) []float64
+
+	DurationSlice(name string) []time.Duration
+	DurationSliceOr(name string, deflt []time.Duration) []time.Duration
+	DurationSliceAnd(name string, pattern string) ([]time.Duration, bool)
+	DurationSliceAndOr(name string, pattern string, deflt []time.Duration) []time.Duration
+
+	StringMap(name string) map[string]interface{}
+	StringMapOr(name string, deflt map[string]interface{}) map[string]interface{}
+	StringMapString(name string) map[string]string
+	StringMapStringOr(name string, deflt map[string]string) map[string]string
+
+	Time(name string, layouts ...string) time.Time
+	TimeOr(name string, deflt time.Time, layouts ...string) time.Time
+	TimeE(name string, layouts ...string) (time.Time, error)
+
+	Location(name string) *time.Location
+	LocationOr(name string, deflt *time.Location) *time.Location
+	LocationE(name string) (*time.Location, error)
+
+	URL(name string) *url.URL
+	URLOr(name string, deflt *url.URL) *url.URL
+	URLE(name string) (*url.URL, error)
+
+	IP(name string) net.IP
+	IPOr(name string, deflt net.IP) net.IP
+	IPE(name string) (net.IP, error)
+
+	IPNet(name string) *net.IPNet
+	IPNetOr(name string, deflt *net.IPNet) *net.IPNet
+	IPNetE(name string) (*net.IPNet, error)
+
+	Prefix(name string) netip.Prefix
+	PrefixOr(name string, deflt netip.Prefix) netip.Prefix
+	PrefixE(name string) (netip.Prefix, error)
+
+	HostPort(name string) (string, int)
+	HostPortOr(name string, host string, port int) (string, int)
+	HostPortE(name string) (string, int, error)
+
+	Regexp(name string) *regexp.Regexp
+	RegexpOr(name string, deflt *regexp.Regexp) *regexp.Regexp
+	RegexpE(name string) (*regexp.Regexp, error)
+
+	FileMode(name string) os.FileMode
+	FileModeOr(name string, deflt os.FileMode) os.FileMode
+	FileModeE(name string) (os.FileMode, error)
+
+	Secret(name string) Secret
+	SecretOr(name string, deflt Secret) Secret
+	SecretE(name string) (Secret, error)
 }
 
 // Valuer is a abstraction for config value, which can convert into multiple types.
 type Valuer interface {
 	Exist() bool
+	As(target interface{}) error
 
 	Raw() interface{}
 	Config() Configer
@@ -73,21 +170,123 @@ type Valuer interface {
 	Int64And(pattern string) (int64, bool)
 	Int64AndOr(pattern string, deflt int64) int64
 
+	Uint() uint
+	UintOr(deflt uint) uint
+	UintE() (uint, error)
+	UintAnd(pattern string) (uint, bool)
+	UintAndOr(pattern string, deflt uint) uint
+
+	Uint64() uint64
+	Uint64Or(deflt uint64) uint64
+	Uint64E() (uint64, error)
+	Uint64And(pattern string) (uint64, bool)
+	Uint64AndOr(pattern string, deflt uint64) uint64
+
+	Int32() int32
+	Int32Or(deflt int32) int32
+	Int32E() (int32, error)
+	Int32And(pattern string) (int32, bool)
+	Int32AndOr(pattern string, deflt int32) int32
+
 	Float() float64
 	FloatOr(deflt float64) float64
 	FloatAnd(pattern string) (float64, bool)
 	FloatAndOr(pattern string, deflt float64) float64
 
+	Float32() float32
+	Float32Or(deflt float32) float32
+	Float32E() (float32, error)
+	Float32And(pattern string) (float32, bool)
+	Float32AndOr(pattern string, deflt float32) float32
+
 	Duration() time.Duration
 	DurationOr(deflt int64) time.Duration
 	DurationAnd(pattern string) (time.Duration, bool)
 	DurationAndOr(pattern string, deflt int64) time.Duration
+
+	StringSlice() []string
+	StringSliceOr(deflt []string) []string
+	StringSliceAnd(pattern string) ([]string, bool)
+	StringSliceAndOr(pattern string, deflt []string) []string
+
+	IntSlice() []int
+	IntSliceOr(deflt []int) []int
+	IntSliceAnd(pattern string) ([]int, bool)
+	IntSliceAndOr(pattern string, deflt []int) []int
+
+	FloatSlice() []float64
+	FloatSliceOr(deflt []float64) []float64
+	FloatSliceAnd(pattern string) ([]float64, bool)
+	FloatSliceAndOr(pattern string, deflt []float64) []float64
+
+	DurationSlice() []time.Duration
+	DurationSliceOr(deflt []time.Duration) []time.Duration
+	DurationSliceAnd(pattern string) ([]time.Duration, bool)
+	DurationSliceAndOr(pattern string, deflt []time.Duration) []time.Duration
+
+	StringMap() map[string]interface{}
+	StringMapOr(deflt map[string]interface{}) map[string]interface{}
+	StringMapString() map[string]string
+	StringMapStringOr(deflt map[string]string) map[string]string
+
+	Time(layouts ...string) time.Time
+	TimeOr(deflt time.Time, layouts ...string) time.Time
+	TimeE(layouts ...string) (time.Time, error)
+
+	Location() *time.Location
+	LocationOr(deflt *time.Location) *time.Location
+	LocationE() (*time.Location, error)
+
+	URL() *url.URL
+	URLOr(deflt *url.URL) *url.URL
+	URLE() (*url.URL, error)
+
+	IP() net.IP
+	IPOr(deflt net.IP) net.IP
+	IPE() (net.IP, error)
+
+	IPNet() *net.IPNet
+	IPNetOr(deflt *net.IPNet) *net.IPNet
+	IPNetE() (*net.IPNet, error)
+
+	Prefix() netip.Prefix
+	PrefixOr(deflt netip.Prefix) netip.Prefix
+	PrefixE() (netip.Prefix, error)
+
+	HostPort() (string, int)
+	HostPortOr(host string, port int) (string, int)
+	HostPortE() (string, int, error)
+
+	Regexp() *regexp.Regexp
+	RegexpOr(deflt *regexp.Regexp) *regexp.Regexp
+	RegexpE() (*regexp.Regexp, error)
+
+	FileMode() os.FileMode
+	FileModeOr(deflt os.FileMode) os.FileMode
+	FileModeE() (os.FileMode, error)
+
+	Secret() Secret
+	SecretOr(deflt Secret) Secret
+	SecretE() (Secret, error)
+}
+
+// Unmarshaler is the interface implemented by the types that can decode
+// themselves from the config value, e.g. the rate limit "100/s", it is
+// honored by Config.Decode and Valuer.As.
+type Unmarshaler interface {
+	UnmarshalCC(v Valuer) error
 }
 
 // Patterner is abstraction which do validation work.
 // if pattern is not valid, then the following methods will always return false.
 //
-// string pattern use the native regular expression to validate the value.
+// string pattern use the native regular expression to validate the value,
+// or one of the prefixed patterns:
+//     "enum:debug|info|warn"  one of the values
+//     "glob:*.example.com"    shell file name pattern, see path.Match
+//     "len:N>=8&&N<=64"       condition on the length(in runes)
+//     "format:email"          built-in format, one of email, hostname,
+//                             uri, ipv4, cidr and semver
 //
 // int(time.Duration) and float64 pattern use the basic if-like conditions to calculate and validate
 // the value, use 'N' as placeholder for number, bit operation is not supported,
@@ -96,9 +295,30 @@ type Valuer interface {
 //     "N>1&&N<=5"
 //     "N<1||N>3"
 //     "(N%2==0)&&(N<=4||N>=8)"
+//     "N in [30, 80]" or "N in (0, 1]"
+//     "N in {1, 2, 5}" or "N not in {1, 2, 5}"
+//     "N>100 ? N%10==0 : N>0"
+//
+// Patterners can be composed by All, Any and Not, or by a list of patterns
+// in config, which means all the patterns must be valid.
 type Patterner interface {
 	Err() error
 	ValidateInt(n int) bool
 	ValidateFloat(n float64) bool
 	ValidateString(s string) bool
 }
+
+// PatternerE is a Patterner which describes why the value is not valid, the
+// E-suffixed methods return the error. All the Patterners created by cc
+// implement it:
+//
+//	if p, ok := c.Pattern("port").(cc.PatternerE); ok {
+//		err = p.ValidateIntE(n)
+//	}
+type PatternerE interface {
+	Patterner
+	String() string
+	ValidateIntE(n int) error
+	ValidateFloatE(n float64) error
+	ValidateStringE(s string) error
+}
@@ -0,0 +1,324 @@
+// Command cc inspects, queries and validates the config files with the same
+// semantics as the cc library, e.g.
+//
+//	cc get config.yaml map.child.key_three
+//	cc merge base.yaml prod.yaml -o json
+//	cc validate -schema schema.json config.yaml
+//	cc check-pattern 'N>=30&&N<=80' 40
+//	cc env -prefix MYAPP config.yaml
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/damnever/cc"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const usage = `usage: cc <command> [arguments]
+
+commands:
+  get [-o yaml|json] <file>... <key>      print the value by dotted key, e.g. map.child.key_three or list.0
+  merge [-o yaml|json] <file>...          merge the files in order and print the result
+  validate [-schema file] <file>...       validate the merged files against the JSON Schema
+  check-pattern <pattern> <value>...      check the values against the pattern
+  env [-prefix P] [-a] <file>...          show the environment variables which override the top level keys
+`
+
+// errFailed indicates the command has printed the failures.
+var errFailed = errors.New("failed")
+
+type command func(args []string, w io.Writer) error
+
+var commands = map[string]command{
+	"get":           cmdGet,
+	"merge":         cmdMerge,
+	"validate":      cmdValidate,
+	"check-pattern": cmdCheckPattern,
+	"env":           cmdEnv,
+}
+
+func main() {
+	// The library parses the command line flags on creating Config, mark
+	// them parsed since the commands have their own flags.
+	flag.CommandLine.Parse(nil)
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "cc: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err := cmd(os.Args[2:], os.Stdout); err != nil {
+		if err != errFailed {
+			fmt.Fprintf(os.Stderr, "cc %s: %v\n", os.Args[1], err)
+		}
+		os.Exit(1)
+	}
+}
+
+// parseArgs parses the flags which may be mixed with the positional
+// arguments, returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(ioutil.Discard)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// load merges the files in order, returns the Config and the data with
+// references resolved.
+func load(files []string) (*cc.Config, map[string]interface{}, error) {
+	if len(files) == 0 {
+		return nil, nil, errors.New("no config files")
+	}
+	c := cc.NewConfig()
+	for _, fpath := range files {
+		if err := c.MergeFromFile(fpath); err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", fpath, err)
+		}
+	}
+	kv, err := c.Resolve()
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, kv, nil
+}
+
+func cmdGet(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	output := fs.String("o", "", "the output format of maps and lists, yaml or json")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 2 {
+		return errors.New("usage: cc get [-o yaml|json] <file>... <key>")
+	}
+	_, kv, err := load(args[:len(args)-1])
+	if err != nil {
+		return err
+	}
+
+	key := args[len(args)-1]
+	v, ok := lookup(kv, strings.Split(key, "."))
+	if !ok {
+		return fmt.Errorf("%s: not found", key)
+	}
+	switch v.(type) {
+	case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+	default:
+		if *output == "" {
+			_, err = fmt.Fprintln(w, v)
+			return err
+		}
+	}
+	return write(w, v, *output)
+}
+
+// lookup finds the value by path, the numbers are used as list indexes.
+func lookup(v interface{}, path []string) (interface{}, bool) {
+	for _, name := range path {
+		switch x := plain(v).(type) {
+		case map[string]interface{}:
+			e, ok := x[name]
+			if !ok {
+				return nil, false
+			}
+			v = e
+		case []interface{}:
+			i, err := strconv.Atoi(name)
+			if err != nil || i < 0 || i >= len(x) {
+				return nil, false
+			}
+			v = x[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func cmdMerge(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	output := fs.String("o", "yaml", "the output format, yaml or json")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	_, kv, err := load(args)
+	if err != nil {
+		return err
+	}
+	return write(w, kv, *output)
+}
+
+func cmdValidate(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	schema := fs.String("schema", "", "the JSON Schema file")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	c, _, err := load(args)
+	if err != nil {
+		return err
+	}
+	if *schema == "" {
+		_, err = fmt.Fprintln(w, "ok")
+		return err
+	}
+	data, err := ioutil.ReadFile(*schema)
+	if err != nil {
+		return err
+	}
+	switch err := c.ValidateSchema(data).(type) {
+	case nil:
+		_, err = fmt.Fprintln(w, "ok")
+		return err
+	case cc.SchemaErrors:
+		for _, e := range err {
+			fmt.Fprintln(w, e)
+		}
+		return errFailed
+	default:
+		return err
+	}
+}
+
+func cmdCheckPattern(args []string, w io.Writer) error {
+	if len(args) < 2 {
+		return errors.New("usage: cc check-pattern <pattern> <value>...")
+	}
+	p, err := cc.CompilePattern(args[0])
+	if err != nil {
+		return err
+	}
+	failed := false
+	for _, value := range args[1:] {
+		if p.IsCondition() {
+			var n float64
+			if n, err = strconv.ParseFloat(value, 64); err == nil {
+				err = p.ValidateFloatE(n)
+			}
+		} else {
+			err = p.ValidateStringE(value)
+		}
+		if err != nil {
+			failed = true
+			fmt.Fprintf(w, "%s: %v\n", value, err)
+		} else {
+			fmt.Fprintf(w, "%s: ok\n", value)
+		}
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
+
+func cmdEnv(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("env", flag.ContinueOnError)
+	prefix := fs.String("prefix", "", "the prefix of the environment variables, e.g. MYAPP for MYAPP_PORT")
+	all := fs.Bool("a", false, "show the unset environment variables too")
+	args, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	c, kv, err := load(args)
+	if err != nil {
+		return err
+	}
+	c.SetEnvPrefix(*prefix)
+
+	keys := make([]string, 0, len(kv))
+	for k, v := range kv {
+		switch v.(type) {
+		case map[string]interface{}, map[interface{}]interface{}, []interface{}, cc.Configer:
+			continue // the environment variables only apply to the scalars
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tENV\tFILE\tEFFECTIVE")
+	for _, k := range keys {
+		env := c.EnvName(k)
+		value, set := os.LookupEnv(env)
+		if set && value != "" {
+			fmt.Fprintf(tw, "%s\t%s\t%v\t%s\n", k, env, kv[k], c.String(k))
+		} else if *all {
+			fmt.Fprintf(tw, "%s\t%s\t%v\t(unset)\n", k, env, kv[k])
+		}
+	}
+	return tw.Flush()
+}
+
+func write(w io.Writer, v interface{}, format string) error {
+	var data []byte
+	var err error
+	switch format {
+	case "yaml", "":
+		data, err = yaml.Marshal(plain(v))
+	case "json":
+		data, err = json.MarshalIndent(plain(v), "", "    ")
+		data = append(data, '\n')
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// plain converts the Configer and map[interface{}]interface{} into
+// map[string]interface{} recursively.
+func plain(v interface{}) interface{} {
+	switch x := v.(type) {
+	case cc.Configer:
+		return plain(x.KV())
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			m[fmt.Sprintf("%v", k)] = plain(e)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			m[k] = plain(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(x))
+		for i, e := range x {
+			l[i] = plain(e)
+		}
+		return l
+	}
+	return v
+}
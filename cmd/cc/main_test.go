@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/damnever/cc/assert"
+)
+
+const example = "../../example/example.yaml"
+
+func run(t *testing.T, cmd command, args ...string) (string, error) {
+	var buf bytes.Buffer
+	err := cmd(args, &buf)
+	return buf.String(), err
+}
+
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	fpath := filepath.Join(dir, name)
+	assert.Must(t, ioutil.WriteFile(fpath, []byte(content), 0644))
+	return fpath
+}
+
+func TestGet(t *testing.T) {
+	out, err := run(t, cmdGet, example, "map.child.key_three")
+	assert.Must(t, err)
+	assert.Check(t, out, "33\n")
+
+	out, err = run(t, cmdGet, example, "list.0")
+	assert.Must(t, err)
+	assert.Check(t, out, "element_one\n")
+
+	out, err = run(t, cmdGet, example, "map.child")
+	assert.Must(t, err)
+	assert.Check(t, out, "key_four: good\nkey_three: 33\n")
+
+	out, err = run(t, cmdGet, example, "map.child", "-o", "json")
+	assert.Must(t, err)
+	assert.Check(t, out, "{\n    \"key_four\": \"good\",\n    \"key_three\": 33\n}\n")
+
+	_, err = run(t, cmdGet, example, "map.nope")
+	assert.Check(t, err.Error(), "map.nope: not found")
+	_, err = run(t, cmdGet, example, "list.9")
+	assert.Check(t, err.Error(), "list.9: not found")
+}
+
+func TestMerge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cc")
+	assert.Must(t, err)
+	defer os.RemoveAll(dir)
+	base := writeFile(t, dir, "base.yaml", "name: base\nport: 80\nmap:\n  a: 1\n  b: 2\nurl: http://${name}\n")
+	prod := writeFile(t, dir, "prod.json", `{"name": "prod", "map": {"a": 3}}`)
+
+	// Same as MergeFromFile, the top level values are replaced.
+	out, err := run(t, cmdMerge, base, prod, "-o", "json")
+	assert.Must(t, err)
+	assert.Check(t, out, `{
+    "map": {
+        "a": 3
+    },
+    "name": "prod",
+    "port": 80,
+    "url": "http://prod"
+}
+`)
+	out, err = run(t, cmdMerge, base, prod)
+	assert.Must(t, err)
+	assert.Check(t, out, "map:\n  a: 3\nname: prod\nport: 80\nurl: http://prod\n")
+
+	_, err = run(t, cmdMerge, base, "-o", "toml")
+	assert.Check(t, err.Error(), "unsupported output format: toml")
+}
+
+func TestValidate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cc")
+	assert.Must(t, err)
+	defer os.RemoveAll(dir)
+	schema := writeFile(t, dir, "schema.json", `{
+		"type": "object",
+		"required": ["name", "port"],
+		"properties": {"name": {"type": "integer"}}
+	}`)
+
+	out, err := run(t, cmdValidate, "-schema", schema, example)
+	assert.Check(t, err, errFailed)
+	assert.Check(t, out, "(root): missing required property \"port\"\n/name: expected integer, got string\n")
+
+	out, err = run(t, cmdValidate, example)
+	assert.Must(t, err)
+	assert.Check(t, out, "ok\n")
+}
+
+func TestCheckPattern(t *testing.T) {
+	out, err := run(t, cmdCheckPattern, "N>=30&&N<=80", "40", "90")
+	assert.Check(t, err, errFailed)
+	assert.Check(t, out, "40: ok\n90: 90 does not satisfy N >= 30 && N <= 80\n")
+
+	out, err = run(t, cmdCheckPattern, "^c", "cc")
+	assert.Must(t, err)
+	assert.Check(t, out, "cc: ok\n")
+
+	out, err = run(t, cmdCheckPattern, "N>0", "x")
+	assert.Check(t, err, errFailed)
+	assert.Check(t, out, "x: strconv.ParseFloat: parsing \"x\": invalid syntax\n")
+
+	_, err = run(t, cmdCheckPattern, "N>0.0.2", "1")
+	if err == nil {
+		t.Fatal("expect error for invalid pattern")
+	}
+}
+
+func TestEnv(t *testing.T) {
+	os.Setenv("MYAPP_NAME", "prod")
+	defer os.Unsetenv("MYAPP_NAME")
+
+	out, err := run(t, cmdEnv, "-prefix", "myapp", example)
+	assert.Must(t, err)
+	assert.Check(t, out, "KEY   ENV         FILE  EFFECTIVE\nname  MYAPP_NAME  cc    prod\n")
+
+	out, err = run(t, cmdEnv, "-a", example)
+	assert.Must(t, err)
+	assert.Check(t, out, "KEY   ENV   FILE  EFFECTIVE\nname  name  cc    (unset)\n")
+}
@@ -0,0 +1,382 @@
+// Command ccgen generates a Go struct with cc tags and a typed loader
+// from a sample YAML or JSON config file, e.g.
+//
+//	ccgen -pkg config -type Config -o config_gen.go example.yaml
+//
+// The types are inferred from the values: integers, floats, bools, strings,
+// durations(strings like "3s"), maps become nested structs and lists of maps
+// become slices of structs.
+//
+// The patterns are turned into validation: if a map has a "patterns" map,
+// the patterns whose keys match the sibling keys become the `pattern` tags of
+// the sibling fields, the others and the keys end with "_pattern" become
+// cc.Patterner fields.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func main() {
+	pkg := flag.String("pkg", "config", "the package name of the generated code")
+	typ := flag.String("type", "Config", "the name of the generated struct")
+	out := flag.String("o", "", "the output file, default to stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: ccgen [flags] <config file>\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	fpath := flag.Arg(0)
+	data, err := readConfig(fpath)
+	if err != nil {
+		fatal(err)
+	}
+	code, err := generate(data, *pkg, *typ, filepath.Base(fpath))
+	if err != nil {
+		fatal(err)
+	}
+	if *out == "" {
+		os.Stdout.Write(code)
+		return
+	}
+	if err := ioutil.WriteFile(*out, code, 0644); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "ccgen: %v\n", err)
+	os.Exit(1)
+}
+
+// readConfig reads the config file, the JSON numbers are kept as json.Number
+// so that the integers and floats can be distinguished.
+func readConfig(fpath string) (map[string]interface{}, error) {
+	content, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+	switch ext := filepath.Ext(fpath); ext {
+	case ".yaml", ".yml":
+		var data map[interface{}]interface{}
+		if err := yaml.Unmarshal(content, &data); err != nil {
+			return nil, err
+		}
+		return toStringMap(data), nil
+	case ".json":
+		var data map[string]interface{}
+		dec := json.NewDecoder(bytes.NewReader(content))
+		dec.UseNumber()
+		if err := dec.Decode(&data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported config file type: %s", fpath)
+	}
+}
+
+func toStringMap(m map[interface{}]interface{}) map[string]interface{} {
+	kv := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		kv[fmt.Sprintf("%v", k)] = v
+	}
+	return kv
+}
+
+type field struct {
+	name    string
+	key     string
+	typ     string
+	pattern string
+}
+
+type structType struct {
+	name   string
+	fields []field
+}
+
+type generator struct {
+	structs []*structType
+	names   map[string]bool
+	imports map[string]bool
+}
+
+func generate(data map[string]interface{}, pkg string, typ string, source string) ([]byte, error) {
+	g := &generator{names: map[string]bool{}, imports: map[string]bool{}}
+	g.structOf(typ, data)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by ccgen from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	buf.WriteString("import (\n")
+	if g.imports["time"] {
+		buf.WriteString("\t\"time\"\n\n")
+	}
+	buf.WriteString("\t\"github.com/damnever/cc\"\n)\n")
+
+	for _, st := range g.structs {
+		if st.name == typ {
+			fmt.Fprintf(&buf, "\n// %s is generated from %s.\n", st.name, source)
+		} else {
+			fmt.Fprintf(&buf, "\n// %s is a part of %s.\n", st.name, typ)
+		}
+		fmt.Fprintf(&buf, "type %s struct {\n", st.name)
+		for _, f := range st.fields {
+			tag := fmt.Sprintf("cc:%s", strconv.Quote(f.key))
+			if f.pattern != "" {
+				tag += fmt.Sprintf(" pattern:%s", strconv.Quote(f.pattern))
+			}
+			fmt.Fprintf(&buf, "%s %s `%s`\n", f.name, f.typ, tag)
+		}
+		buf.WriteString("}\n")
+	}
+
+	fmt.Fprintf(&buf, `
+// Load%[1]s loads the %[1]s from the config file, see cc.NewConfigFromFile.
+func Load%[1]s(fpath string) (*%[1]s, error) {
+	c, err := cc.NewConfigFromFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+	v := &%[1]s{}
+	if err := c.Decode(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+`, typ)
+	return format.Source(buf.Bytes())
+}
+
+// structOf generates the struct for the map, returns the name of the struct.
+func (g *generator) structOf(name string, m map[string]interface{}) string {
+	name = g.uniqueName(name)
+	st := &structType{name: name}
+	g.structs = append(g.structs, st)
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// The patterns match the sibling keys become the tags.
+	tags := map[string]string{}
+	patterns := map[string]interface{}{}
+	if x, ok := toMap(m["patterns"]); ok {
+		for k, v := range x {
+			p, isString := v.(string)
+			if _, in := m[k]; in && isString && k != "patterns" {
+				tags[k] = p
+			} else {
+				patterns[k] = v
+			}
+		}
+	}
+
+	fieldNames := map[string]bool{}
+	for _, k := range keys {
+		var typ string
+		switch {
+		case k == "patterns" && len(tags) > 0:
+			if len(patterns) == 0 {
+				continue
+			}
+			typ = g.patternsStruct(name+"Patterns", patterns)
+		case strings.HasSuffix(k, "_pattern") && isString(m[k]):
+			typ = "cc.Patterner"
+		default:
+			typ = g.typeOf(name+goName(k), m[k])
+		}
+		fname := goName(k)
+		for i := 2; fieldNames[fname]; i++ {
+			fname = goName(k) + strconv.Itoa(i)
+		}
+		fieldNames[fname] = true
+		st.fields = append(st.fields, field{name: fname, key: k, typ: typ, pattern: tags[k]})
+	}
+	return name
+}
+
+// patternsStruct generates the struct which all fields are cc.Patterner.
+func (g *generator) patternsStruct(name string, patterns map[string]interface{}) string {
+	name = g.uniqueName(name)
+	st := &structType{name: name}
+	g.structs = append(g.structs, st)
+	keys := make([]string, 0, len(patterns))
+	for k := range patterns {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		st.fields = append(st.fields, field{name: goName(k), key: k, typ: "cc.Patterner"})
+	}
+	return name
+}
+
+func (g *generator) uniqueName(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+func (g *generator) typeOf(name string, v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "interface{}"
+	case bool:
+		return "bool"
+	case int:
+		if x > math.MaxInt32 || x < math.MinInt32 {
+			return "int64"
+		}
+		return "int"
+	case int64:
+		return "int64"
+	case uint64:
+		return "uint64"
+	case float64:
+		return "float64"
+	case json.Number:
+		if strings.ContainsAny(string(x), ".eE") {
+			return "float64"
+		}
+		n, err := x.Int64()
+		if err != nil {
+			return "float64"
+		}
+		return g.typeOf(name, int(n))
+	case string:
+		if isDuration(x) {
+			g.imports["time"] = true
+			return "time.Duration"
+		}
+		return "string"
+	case []interface{}:
+		return g.sliceOf(name, x)
+	}
+	if m, ok := toMap(v); ok {
+		return g.structOf(name, m)
+	}
+	return "interface{}"
+}
+
+// sliceOf infers the element type of the list, the maps are merged into one struct.
+func (g *generator) sliceOf(name string, list []interface{}) string {
+	if len(list) == 0 {
+		return "[]interface{}"
+	}
+	merged := map[string]interface{}{}
+	allMaps := true
+	for _, e := range list {
+		m, ok := toMap(e)
+		if !ok {
+			allMaps = false
+			break
+		}
+		for k, v := range m {
+			if _, in := merged[k]; !in {
+				merged[k] = v
+			}
+		}
+	}
+	if allMaps {
+		return "[]" + g.structOf(name, merged)
+	}
+
+	types := map[string]bool{}
+	for _, e := range list {
+		if _, ok := toMap(e); ok {
+			return "[]interface{}"
+		}
+		if _, ok := e.([]interface{}); ok {
+			return "[]interface{}"
+		}
+		types[g.typeOf(name, e)] = true
+	}
+	if len(types) == 1 {
+		for typ := range types {
+			return "[]" + typ
+		}
+	}
+	if len(types) == 2 && types["float64"] && (types["int"] || types["int64"]) {
+		return "[]float64"
+	}
+	return "[]interface{}"
+}
+
+func toMap(v interface{}) (map[string]interface{}, bool) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return x, true
+	case map[interface{}]interface{}:
+		return toStringMap(x), true
+	}
+	return nil, false
+}
+
+func isString(v interface{}) bool {
+	_, ok := v.(string)
+	return ok
+}
+
+// isDuration reports whether s is a duration like "3s" or "1h30m".
+func isDuration(s string) bool {
+	if _, err := time.ParseDuration(s); err != nil {
+		return false
+	}
+	return strings.IndexFunc(s, unicode.IsLetter) >= 0
+}
+
+var initialisms = map[string]bool{
+	"api": true, "dns": true, "http": true, "https": true, "id": true, "ip": true,
+	"json": true, "sql": true, "tcp": true, "tls": true, "ttl": true, "udp": true,
+	"uri": true, "url": true, "uuid": true, "yaml": true,
+}
+
+// goName converts the key into an exported Go identifier, e.g.
+// "key_one" becomes "KeyOne", "http-url" becomes "HTTPURL".
+func goName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var buf bytes.Buffer
+	for _, w := range words {
+		if initialisms[strings.ToLower(w)] {
+			buf.WriteString(strings.ToUpper(w))
+			continue
+		}
+		rs := []rune(w)
+		rs[0] = unicode.ToUpper(rs[0])
+		buf.WriteString(string(rs))
+	}
+	name := buf.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestGenerateFromExample(t *testing.T) {
+	for _, fpath := range []string{"../../example/example.yaml", "../../example/example.json"} {
+		data, err := readConfig(fpath)
+		assert.Must(t, err)
+		code, err := generate(data, "config", "Config", "example")
+		assert.Must(t, err)
+		assert.Check(t, string(code), `// Code generated by ccgen from example. DO NOT EDIT.
+
+package config
+
+import (
+	"github.com/damnever/cc"
+)
+
+// Config is generated from example.
+type Config struct {
+	List     []interface{}  `+"`"+`cc:"list"`+"`"+`
+	Map      ConfigMap      `+"`"+`cc:"map"`+"`"+`
+	Name     string         `+"`"+`cc:"name"`+"`"+`
+	Patterns ConfigPatterns `+"`"+`cc:"patterns"`+"`"+`
+}
+
+// ConfigMap is a part of Config.
+type ConfigMap struct {
+	Child  ConfigMapChild `+"`"+`cc:"child"`+"`"+`
+	KeyOne bool           `+"`"+`cc:"key_one"`+"`"+`
+	KeyTwo bool           `+"`"+`cc:"key_two"`+"`"+`
+}
+
+// ConfigMapChild is a part of Config.
+type ConfigMapChild struct {
+	KeyFour  string `+"`"+`cc:"key_four"`+"`"+`
+	KeyThree int    `+"`"+`cc:"key_three"`+"`"+`
+}
+
+// ConfigPatterns is a part of Config.
+type ConfigPatterns struct {
+	FloatPattern  cc.Patterner `+"`"+`cc:"float_pattern"`+"`"+`
+	IntPattern    cc.Patterner `+"`"+`cc:"int_pattern"`+"`"+`
+	StringPattern cc.Patterner `+"`"+`cc:"string_pattern"`+"`"+`
+}
+
+// LoadConfig loads the Config from the config file, see cc.NewConfigFromFile.
+func LoadConfig(fpath string) (*Config, error) {
+	c, err := cc.NewConfigFromFile(fpath)
+	if err != nil {
+		return nil, err
+	}
+	v := &Config{}
+	if err := c.Decode(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+`)
+	}
+}
+
+func TestGenerateTypes(t *testing.T) {
+	data := map[string]interface{}{
+		"http_port": 8080,
+		"big":       int64(1) << 40,
+		"ratio":     0.5,
+		"timeout":   "3s",
+		"hosts":     []interface{}{"a", "b"},
+		"weights":   []interface{}{1, 0.5},
+		"servers": []interface{}{
+			map[interface{}]interface{}{"addr": "x"},
+			map[interface{}]interface{}{"port": 80},
+		},
+		"name_pattern": "^a",
+		"patterns": map[interface{}]interface{}{
+			"http_port": "N>0&&N<65536",
+			"other":     "^b",
+		},
+	}
+	code, err := generate(data, "app", "Settings", "app.yaml")
+	assert.Must(t, err)
+	src := strings.Join(strings.Fields(string(code)), " ")
+	for _, expected := range []string{
+		"import ( \"time\"",
+		"Big int64 `cc:\"big\"`",
+		"HTTPPort int `cc:\"http_port\" pattern:\"N>0&&N<65536\"`",
+		"Hosts []string `cc:\"hosts\"`",
+		"Weights []float64 `cc:\"weights\"`",
+		"Ratio float64 `cc:\"ratio\"`",
+		"Timeout time.Duration `cc:\"timeout\"`",
+		"Servers []SettingsServers `cc:\"servers\"`",
+		"Addr string `cc:\"addr\"` Port int `cc:\"port\"`",
+		"NamePattern cc.Patterner `cc:\"name_pattern\"`",
+		"Patterns SettingsPatterns `cc:\"patterns\"`",
+		"Other cc.Patterner `cc:\"other\"`",
+		"func LoadSettings(fpath string) (*Settings, error) {",
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("expect %q in:\n%s", expected, src)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for key, name := range map[string]string{
+		"key_one":  "KeyOne",
+		"http-url": "HTTPURL",
+		"user.id":  "UserID",
+		"3d":       "X3d",
+		"":         "X",
+	} {
+		assert.Check(t, goName(key), name)
+	}
+}
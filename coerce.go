@@ -0,0 +1,111 @@
+package cc
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Coercion is the policy of converting the values between the types in the
+// getters, see SetCoercion.
+type Coercion int
+
+const (
+	// CoerceStrict only converts the numbers between the numeric types,
+	// e.g. String returns "" for a number, it is the default.
+	CoerceStrict Coercion = iota
+	// CoerceLenient also parses the strings into numbers, bools(see
+	// strconv.ParseBool) and durations(see time.ParseDuration), and formats
+	// the numbers and bools into strings, e.g. Int returns 8080 for "8080".
+	CoerceLenient
+)
+
+// SetCoercion sets the coercion policy for the getters of c, Decode, and
+// the sub Configers and Valuers from c.
+func (c *Config) SetCoercion(coercion Coercion) {
+	c.coercion = coercion
+}
+
+// coercionPolicy returns the coercion policy of the root Config.
+func (c *Config) coercionPolicy() Coercion {
+	return c.rootConfig().coercion
+}
+
+// coercionOf returns the coercion policy for the value by name, the whole
+// value environment variable and file references are always strings, so they
+// are converted leniently as the bound environment variables.
+func (c *Config) coercionOf(name string) Coercion {
+	if s, ok := c.kv[name].(string); ok && isTextReference(s) {
+		return CoerceLenient
+	}
+	return c.coercionPolicy()
+}
+
+// kind converts v for the target kind if lenient.
+func (co Coercion) kind(v interface{}, kind reflect.Kind) interface{} {
+	switch {
+	case kind == reflect.String:
+		return co.text(v)
+	case kind == reflect.Bool:
+		return co.boolean(v)
+	case kind >= reflect.Int && kind <= reflect.Float64:
+		return co.numeric(v)
+	}
+	return v
+}
+
+// numeric parses the string v into json.Number if lenient.
+func (co Coercion) numeric(v interface{}) interface{} {
+	if s, ok := v.(string); ok && co == CoerceLenient {
+		s = strings.TrimSpace(s)
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			return json.Number(s)
+		}
+	}
+	return v
+}
+
+// boolean parses the string v into bool if lenient.
+func (co Coercion) boolean(v interface{}) interface{} {
+	if s, ok := v.(string); ok && co == CoerceLenient {
+		if b, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+// text formats the number or bool v into string if lenient.
+func (co Coercion) text(v interface{}) interface{} {
+	if co != CoerceLenient {
+		return v
+	}
+	switch x := v.(type) {
+	case bool:
+		return strconv.FormatBool(x)
+	case json.Number:
+		return string(x)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'g', -1, 32)
+	}
+	if n, ok := asInt64(v); ok {
+		return strconv.FormatInt(n, 10)
+	}
+	if n, ok := asUint64(v); ok {
+		return strconv.FormatUint(n, 10)
+	}
+	return v
+}
+
+// duration parses the duration string v if lenient, e.g. "1m30s".
+func (co Coercion) duration(v interface{}) (time.Duration, bool) {
+	if s, ok := v.(string); ok && co == CoerceLenient {
+		d, err := time.ParseDuration(strings.TrimSpace(s))
+		return d, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,97 @@
+package cc
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestCoercion(t *testing.T) {
+	c, err := NewConfigFromYAML([]byte(`
+port: "8080"
+big: " 18446744073709551615 "
+ratio: "0.5"
+debug: "true"
+timeout: 1m30s
+nanos: "1000"
+num: 42
+float: 1.5
+enabled: true
+server:
+  port: "9090"
+  timeout: 5s
+list: ["1", "2"]
+`))
+	assert.Must(t, err)
+
+	// Strict by default.
+	assert.Check(t, c.IntOr("port", 1), 1)
+	assert.Check(t, c.BoolOr("debug", false), false)
+	assert.Check(t, c.String("num"), "")
+	assert.Check(t, c.Duration("timeout"), time.Duration(0))
+	assert.Check(t, c.Value("port").IntOr(1), 1)
+	server := c.Config("server")
+
+	c.SetCoercion(CoerceLenient)
+	assert.Check(t, c.Int("port"), 8080)
+	assert.Check(t, c.Int64("port"), int64(8080))
+	assert.Check(t, c.Uint("port"), uint(8080))
+	assert.Check(t, c.Uint64("big"), uint64(18446744073709551615))
+	assert.Check(t, c.Float("ratio"), 0.5)
+	assert.Check(t, c.Float32("ratio"), float32(0.5))
+	assert.Check(t, c.Bool("debug"), true)
+	assert.Check(t, c.String("num"), "42")
+	assert.Check(t, c.String("float"), "1.5")
+	assert.Check(t, c.String("enabled"), "true")
+	assert.Check(t, c.String("big"), " 18446744073709551615 ")
+	assert.Check(t, c.Duration("timeout"), 90*time.Second)
+	assert.Check(t, c.Duration("nanos"), time.Duration(1000))
+	assert.Check(t, c.IntAndOr("port", "N<1024", 80), 80)
+	assert.Check(t, c.DurationAndOr("timeout", "N>0", 1), 90*time.Second)
+	assert.Check(t, c.StringAndOr("num", "^[0-9]+$", ""), "42")
+	n, err := c.Int32E("port")
+	assert.Must(t, err)
+	assert.Check(t, n, int32(8080))
+
+	// The sub Configers and Valuers, including the ones created before.
+	assert.Check(t, server.Int("port"), 9090)
+	assert.Check(t, c.Config("server").Duration("timeout"), 5*time.Second)
+	assert.Check(t, c.Value("server").Config().Int("port"), 9090)
+	assert.Check(t, c.Value("port").Int(), 8080)
+	assert.Check(t, c.Value("timeout").Duration(), 90*time.Second)
+	assert.Check(t, c.Value("list").List()[1].Int(), 2)
+	assert.Check(t, c.Value("server").Map()["port"].Uint(), uint(9090))
+	assert.Check(t, c.Value("num").String(), "42")
+	assert.Check(t, NewValue("8080").IntOr(1), 1)
+
+	var s struct {
+		Port    uint16        `cc:"port"`
+		Debug   bool          `cc:"debug"`
+		Num     string        `cc:"num"`
+		Timeout time.Duration `cc:"timeout"`
+		Nanos   time.Duration `cc:"nanos"`
+		Server  struct {
+			Port int `cc:"port"`
+		} `cc:"server"`
+	}
+	assert.Must(t, c.Decode(&s))
+	assert.Check(t, s.Port, uint16(8080))
+	assert.Check(t, s.Debug, true)
+	assert.Check(t, s.Num, "42")
+	assert.Check(t, s.Timeout, 90*time.Second)
+	assert.Check(t, s.Nanos, time.Duration(1000))
+	assert.Check(t, s.Server.Port, 9090)
+
+	os.Setenv("CC_TEST_BOOL", "false")
+	os.Setenv("CC_TEST_DURATION", "2s")
+	defer os.Unsetenv("CC_TEST_BOOL")
+	defer os.Unsetenv("CC_TEST_DURATION")
+	c.BindEnv("debug", "CC_TEST_BOOL")
+	c.BindEnv("timeout", "CC_TEST_DURATION")
+	assert.Check(t, c.Bool("debug"), false)
+	assert.Check(t, c.Duration("timeout"), 2*time.Second)
+	c.SetCoercion(CoerceStrict)
+	assert.Check(t, c.Bool("debug"), true)
+}
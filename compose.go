@@ -0,0 +1,274 @@
+package cc
+
+import (
+	"fmt"
+	"strings"
+)
+
+type composedPattern struct {
+	op       string
+	patterns []Patterner
+}
+
+// All creates a Patterner which is valid only if all the patterns are valid,
+// the error reports the first failed sub-pattern.
+func All(patterns ...Patterner) PatternerE {
+	return &composedPattern{op: "all", patterns: patterns}
+}
+
+// Any creates a Patterner which is valid if any of the patterns is valid,
+// the error reports all the failed sub-patterns.
+func Any(patterns ...Patterner) PatternerE {
+	return &composedPattern{op: "any", patterns: patterns}
+}
+
+// Not creates a Patterner which is valid only if the pattern is not valid.
+// NOTE: the value is never valid if the pattern itself is wrong.
+func Not(pattern Patterner) PatternerE {
+	return &composedPattern{op: "not", patterns: []Patterner{pattern}}
+}
+
+// Err returns the error of the first wrong sub-pattern.
+func (p *composedPattern) Err() error {
+	for i, sub := range p.patterns {
+		if err := sub.Err(); err != nil {
+			return fmt.Errorf("%s[%d]: %v", p.op, i, err)
+		}
+	}
+	return nil
+}
+
+// String returns the composed pattern like "all(N > 1, N < 5)".
+func (p *composedPattern) String() string {
+	subs := make([]string, len(p.patterns))
+	for i, sub := range p.patterns {
+		subs[i] = patternString(sub)
+	}
+	return p.op + "(" + strings.Join(subs, ", ") + ")"
+}
+
+func (p *composedPattern) ValidateInt(n int) bool {
+	return p.ValidateIntE(n) == nil
+}
+
+func (p *composedPattern) ValidateFloat(n float64) bool {
+	return p.ValidateFloatE(n) == nil
+}
+
+func (p *composedPattern) ValidateString(s string) bool {
+	return p.ValidateStringE(s) == nil
+}
+
+func (p *composedPattern) ValidateIntE(n int) error {
+	return p.validate(n, func(sub Patterner) error { return validateIntE(sub, n) })
+}
+
+func (p *composedPattern) ValidateFloatE(n float64) error {
+	return p.validate(n, func(sub Patterner) error { return validateFloatE(sub, n) })
+}
+
+func (p *composedPattern) ValidateStringE(s string) error {
+	return p.validate(s, func(sub Patterner) error { return validateStringE(sub, s) })
+}
+
+func (p *composedPattern) validate(value interface{}, validate func(Patterner) error) error {
+	switch p.op {
+	case "all":
+		for i, sub := range p.patterns {
+			if err := validate(sub); err != nil {
+				return &ComposedError{Op: p.op, Value: value, Pattern: p.String(), Index: i, Errs: []error{err}}
+			}
+		}
+		return nil
+	case "any":
+		errs := make([]error, 0, len(p.patterns))
+		for _, sub := range p.patterns {
+			err := validate(sub)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		return &ComposedError{Op: p.op, Value: value, Pattern: p.String(), Index: -1, Errs: errs}
+	default: // not
+		sub := p.patterns[0]
+		if sub.Err() != nil {
+			return &ComposedError{Op: p.op, Value: value, Pattern: p.String(), Index: 0, Errs: []error{sub.Err()}}
+		}
+		if validate(sub) == nil {
+			return &ComposedError{Op: p.op, Value: value, Pattern: p.String(), Index: 0}
+		}
+		return nil
+	}
+}
+
+// patternString returns the pattern of p if it is a PatternerE, or the type.
+func patternString(p Patterner) string {
+	if pe, ok := p.(PatternerE); ok {
+		return pe.String()
+	}
+	return fmt.Sprintf("%T", p)
+}
+
+// validateIntE validates n by the ValidateIntE of p if it is a PatternerE,
+// or returns a *ValidationError without the details.
+func validateIntE(p Patterner, n int) error {
+	if pe, ok := p.(PatternerE); ok {
+		return pe.ValidateIntE(n)
+	}
+	return patternError(p, p.ValidateInt(n), n)
+}
+
+// validateFloatE is like validateIntE, but for float64.
+func validateFloatE(p Patterner, n float64) error {
+	if pe, ok := p.(PatternerE); ok {
+		return pe.ValidateFloatE(n)
+	}
+	return patternError(p, p.ValidateFloat(n), n)
+}
+
+// validateStringE is like validateIntE, but for string.
+func validateStringE(p Patterner, s string) error {
+	if pe, ok := p.(PatternerE); ok {
+		return pe.ValidateStringE(s)
+	}
+	return patternError(p, p.ValidateString(s), s)
+}
+
+func patternError(p Patterner, valid bool, value interface{}) error {
+	if valid {
+		return nil
+	}
+	return &ValidationError{Value: value, Pattern: patternString(p), Err: p.Err()}
+}
+
+// ComposedError describes why a value failed to satisfy a composed pattern.
+type ComposedError struct {
+	// Op is one of "all", "any" and "not".
+	Op string
+	// Value is the value being validated.
+	Value interface{}
+	// Pattern is the composed pattern.
+	Pattern string
+	// Index is the index of the failed sub-pattern, -1 for "any".
+	Index int
+	// Errs are the errors of the failed sub-patterns, it is empty for "not"
+	// if the sub-pattern is valid.
+	Errs []error
+}
+
+func (e *ComposedError) Error() string {
+	value := fmt.Sprintf("%v", e.Value)
+	if s, ok := e.Value.(string); ok {
+		value = fmt.Sprintf("%q", s)
+	}
+	switch {
+	case e.Op == "any":
+		errs := make([]string, len(e.Errs))
+		for i, err := range e.Errs {
+			errs[i] = err.Error()
+		}
+		return fmt.Sprintf("%s does not satisfy %s: %s", value, e.Pattern, strings.Join(errs, "; "))
+	case len(e.Errs) == 0:
+		return fmt.Sprintf("%s does not satisfy %s", value, e.Pattern)
+	default:
+		return fmt.Sprintf("%s does not satisfy %s: %s[%d]: %v", value, e.Pattern, e.Op, e.Index, e.Errs[0])
+	}
+}
+
+// Unwrap returns the error of the failed sub-pattern if only one failed.
+func (e *ComposedError) Unwrap() error {
+	if len(e.Errs) == 1 {
+		return e.Errs[0]
+	}
+	return nil
+}
+
+// badPattern is a Patterner which is always invalid.
+type badPattern struct {
+	err error
+}
+
+func (p *badPattern) Err() error {
+	return p.err
+}
+
+func (p *badPattern) String() string {
+	return "<invalid>"
+}
+
+func (p *badPattern) ValidateInt(n int) bool {
+	return false
+}
+
+func (p *badPattern) ValidateFloat(n float64) bool {
+	return false
+}
+
+func (p *badPattern) ValidateString(s string) bool {
+	return false
+}
+
+func (p *badPattern) ValidateIntE(n int) error {
+	return p.validateE(n)
+}
+
+func (p *badPattern) ValidateFloatE(n float64) error {
+	return p.validateE(n)
+}
+
+func (p *badPattern) ValidateStringE(s string) error {
+	return p.validateE(s)
+}
+
+func (p *badPattern) validateE(value interface{}) error {
+	return &ValidationError{Value: value, Pattern: p.String(), Err: p.err}
+}
+
+// newPatternFrom creates a Patterner from the raw config value:
+//   - a string is a normal pattern
+//   - a list means all the patterns must be valid
+//   - a map with single key "all", "any" or "not" composes the patterns
+//     in the value, "not" with a list negates all of them
+func newPatternFrom(v interface{}) Patterner {
+	switch x := v.(type) {
+	case string:
+		return NewPattern(x)
+	case Patterner:
+		return x
+	case []interface{}:
+		return All(newPatternsFrom(x)...)
+	case map[interface{}]interface{}:
+		return newPatternFrom(unknownMapToStringMap(x))
+	case map[string]interface{}:
+		if len(x) != 1 {
+			break
+		}
+		for op, sub := range x {
+			subs, ok := sub.([]interface{})
+			if !ok {
+				subs = []interface{}{sub}
+			}
+			switch op {
+			case "all":
+				return All(newPatternsFrom(subs)...)
+			case "any":
+				return Any(newPatternsFrom(subs)...)
+			case "not":
+				if len(subs) != 1 {
+					return Not(All(newPatternsFrom(subs)...))
+				}
+				return Not(newPatternFrom(subs[0]))
+			}
+		}
+	}
+	return &badPattern{err: fmt.Errorf("invalid pattern: %v", v)}
+}
+
+func newPatternsFrom(vs []interface{}) []Patterner {
+	patterns := make([]Patterner, len(vs))
+	for i, v := range vs {
+		patterns[i] = newPatternFrom(v)
+	}
+	return patterns
+}
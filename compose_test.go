@@ -0,0 +1,95 @@
+package cc
+
+import (
+	"testing"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestComposePattern(t *testing.T) {
+	{
+		p := All(NewPattern("N>=30"), NewPattern("N%2==0"))
+		assert.Must(t, p.Err())
+		assert.Check(t, p.ValidateInt(40), true)
+		assert.Check(t, p.ValidateInt(41), false)
+		assert.Check(t, p.ValidateInt(20), false)
+		err := p.ValidateIntE(41).(*ComposedError)
+		assert.Check(t, err.Index, 1)
+		assert.Check(t, err.Error(), "41 does not satisfy all(N >= 30, N % 2 == 0): all[1]: 41 does not satisfy N % 2 == 0")
+	}
+	{
+		p := Any(NewPattern("^a"), NewPattern("enum:x|y"))
+		assert.Check(t, p.ValidateString("abc"), true)
+		assert.Check(t, p.ValidateString("y"), true)
+		err := p.ValidateStringE("z").(*ComposedError)
+		assert.Check(t, len(err.Errs), 2)
+		assert.Check(t, err.Error(), `"z" does not satisfy any(^a, enum:x|y): "z" does not match ^a; "z" does not match enum:x|y`)
+	}
+	{
+		p := Not(All(NewPattern("N>1"), NewPattern("N<5")))
+		assert.Check(t, p.ValidateFloat(3), false)
+		assert.Check(t, p.ValidateFloat(6), true)
+		assert.Check(t, p.ValidateFloatE(3).Error(), "3 does not satisfy not(all(N > 1, N < 5))")
+	}
+	{
+		p := All(NewPattern("N>1"), NewPattern("N>"))
+		if p.Err() == nil {
+			t.Fatal("expect error, got nothing")
+		}
+		assert.Check(t, p.ValidateInt(3), false)
+		assert.Check(t, Not(NewPattern("N>")).ValidateInt(3), false)
+	}
+}
+
+func TestConfigComposedPattern(t *testing.T) {
+	c, err := NewConfigFromYAML([]byte(`
+threshold:
+  - "N>=30"
+  - "N<=80"
+  - not: "N in {50, 60}"
+host:
+  any: ["format:ipv4", "glob:*.example.com"]
+bad:
+  foo: "N>1"
+`))
+	assert.Must(t, err)
+
+	p := c.Pattern("threshold")
+	assert.Must(t, p.Err())
+	assert.Check(t, p.ValidateInt(40), true)
+	assert.Check(t, p.ValidateInt(50), false)
+	assert.Check(t, p.ValidateInt(90), false)
+	assert.Check(t, p.(PatternerE).String(), "all(N >= 30, N <= 80, not(N in {50, 60}))")
+	assert.Check(t, c.Value("threshold").Pattern().ValidateInt(40), true)
+
+	p = c.Pattern("host")
+	assert.Check(t, p.ValidateString("127.0.0.1"), true)
+	assert.Check(t, p.ValidateString("www.example.com"), true)
+	assert.Check(t, p.ValidateString("example.org"), false)
+
+	p = c.Pattern("bad")
+	if p.Err() == nil {
+		t.Fatal("expect error, got nothing")
+	}
+	assert.Check(t, p.ValidateInt(2), false)
+}
+
+// evenPattern only implements Patterner, not PatternerE.
+type evenPattern struct{}
+
+func (evenPattern) Err() error                   { return nil }
+func (evenPattern) ValidateInt(n int) bool       { return n%2 == 0 }
+func (evenPattern) ValidateFloat(n float64) bool { return int(n)%2 == 0 }
+func (evenPattern) ValidateString(s string) bool { return false }
+
+func TestComposePatterner(t *testing.T) {
+	var p Patterner = evenPattern{}
+	_, ok := p.(PatternerE)
+	assert.Check(t, ok, false)
+
+	all := All(NewPattern("N>0"), p)
+	assert.Check(t, all.ValidateInt(4), true)
+	assert.Check(t, all.ValidateInt(3), false)
+	assert.Check(t, all.String(), "all(N > 0, cc.evenPattern)")
+	assert.Check(t, all.ValidateIntE(3).Error(), "3 does not satisfy all(N > 0, cc.evenPattern): all[1]: 3 does not satisfy cc.evenPattern")
+}
@@ -1,23 +1,45 @@
 package cc
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"math"
 	"os"
-	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 )
 
 // Config implements the Configer interface.
 // The priorities: flag > environment variables > normal configs.
-// Only the String/Bool/Int/Float/Duration family use environment variables
-// and flags, if any environment variable with same name is set and
+// Only the String/Bool/Int/Uint/Float/Duration family use environment
+// variables and flags, if any environment variable with same name is set and
 // it isn't empty, the Bool/BoolOr will return true.
 // NOTE: we take empty string, false boolean and zero number value as default
 // value in flags, and those value has no priority.
 type Config struct {
 	flags map[string]interface{}
 	kv    map[string]interface{}
+	// envs and flagNames are the names of environment variables and
+	// flags bound to the keys, see BindEnv and BindFlag.
+	envs      map[string]string
+	flagNames map[string]string
+	// root is the Config which the references are resolved from,
+	// nil for the root itself.
+	root         *Config
+	includeOrder IncludeOrder
+	lenientJSON  bool
+	coercion     Coercion
+	envPrefix    string
+	// secretProviders are the providers of the secret references by source,
+	// see SetSecretProvider.
+	secretProviders map[string]SecretProvider
+	// sources are the files which the values are loaded from by Load.
+	sources map[string]string
+	// dotenv is the environment variables layer, see DotenvAsEnv.
+	dotenv map[string]string
 }
 
 func newConfig() *Config {
@@ -62,8 +84,8 @@ func NewConfigFromYAML(data []byte) (*Config, error) {
 	return c, nil
 }
 
-// NewConfigFromFile creates a Config from a config file, extension must be
-// one of ".yaml", ".yml" or ".json".
+// NewConfigFromFile creates a Config from a config file, see MergeFromFile
+// for the supported extensions.
 func NewConfigFromFile(fpath string) (*Config, error) {
 	c := NewConfig()
 	if err := c.MergeFromFile(fpath); err != nil {
@@ -77,30 +99,124 @@ func (c *Config) ParseFlags() {
 	c.flags = parseFlags()
 }
 
-// MergeFromFile merges config data from file, the new config will replace
-// the old. File extension must be one of ".yaml", ".yaml" or ".json".
-func (c *Config) MergeFromFile(fpath string) error {
-	var data map[string]interface{}
-	var err error
-
-	ext := filepath.Ext(fpath)
-	switch ext {
-	case ".yaml", ".yml":
-		data, err = unmarshalYAMLFile(fpath)
-	case ".json":
-		data, err = unmarshalJSONFile(fpath)
-	case "":
-		err = fmt.Errorf("can not determine the config file type: %s", fpath)
-	default:
-		err = fmt.Errorf("unsupported config file type: %s", fpath)
+// ParseFlagSet merges the flags from a parsed FlagSet, the value from
+// same name will be replaced.
+func (c *Config) ParseFlagSet(fs *flag.FlagSet) {
+	if c.flags == nil {
+		c.flags = map[string]interface{}{}
 	}
-	if err != nil {
-		return err
+	for k, v := range parseFlagSet(fs) {
+		c.flags[k] = v
 	}
-	for k, v := range data {
-		c.kv[k] = v
+}
+
+// BindEnv binds the environment variable env to name, so that the getters
+// read env instead of name from environment variables.
+func (c *Config) BindEnv(name string, env string) {
+	if c.envs == nil {
+		c.envs = map[string]string{}
 	}
-	return nil
+	c.envs[name] = env
+}
+
+// BindFlag binds the flag to name, so that the getters read the flag
+// instead of name from flags.
+func (c *Config) BindFlag(name string, flag string) {
+	if c.flagNames == nil {
+		c.flagNames = map[string]string{}
+	}
+	c.flagNames[name] = flag
+}
+
+// SetEnvPrefix sets the prefix of the environment variables for the getters
+// of c and the sub Configs from c, e.g. with the prefix "MYAPP", the key
+// "db_host" reads MYAPP_DB_HOST, see EnvName. The variables bound by BindEnv
+// are not prefixed.
+func (c *Config) SetEnvPrefix(prefix string) {
+	c.envPrefix = prefix
+}
+
+// EnvName returns the name of the environment variable which overrides name,
+// it is the bound one, name itself without prefix, or the upper-cased
+// PREFIX_NAME whose non-alphanumeric characters are replaced by '_'.
+func (c *Config) EnvName(name string) string {
+	if env, ok := c.envs[name]; ok {
+		return env
+	}
+	prefix := c.rootConfig().envPrefix
+	if prefix == "" {
+		return name
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, prefix+"_"+name)
+}
+
+func (c *Config) getenv(name string) string {
+	return c.lookupEnv(c.EnvName(name))
+}
+
+// lookupEnv returns the environment variable, or the one from the dotenv
+// files of the root Config.
+func (c *Config) lookupEnv(env string) string {
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+	return c.rootConfig().dotenv[env]
+}
+
+func (c *Config) lookupFlag(name string) (interface{}, bool) {
+	if f, ok := c.flagNames[name]; ok {
+		name = f
+	}
+	v, ok := c.flags[name]
+	return v, ok
+}
+
+func (c *Config) flag(name string) interface{} {
+	v, _ := c.lookupFlag(name)
+	return v
+}
+
+// number returns the number by name from the non-zero flag, the environment
+// variable as json.Number, or the config data, in order.
+func (c *Config) number(name string) (interface{}, bool) {
+	if v, ok := c.lookupFlag(name); ok {
+		if n, isNum := asFloat64(v); isNum && n != 0 {
+			return v, true
+		}
+	}
+	if env := c.getenv(name); env != "" {
+		if _, err := strconv.ParseFloat(env, 64); err == nil {
+			return json.Number(env), true
+		}
+	}
+	v, ok := c.lookup(name)
+	return c.coercionOf(name).numeric(v), ok
+}
+
+// numberE is like number, but returns an error if not found.
+func (c *Config) numberE(name string) (interface{}, error) {
+	if v, ok := c.number(name); ok && v != nil {
+		return v, nil
+	}
+	return nil, fmt.Errorf("%s: not found", name)
+}
+
+// MergeFromFile merges config data from file, the new config will replace
+// the old. File extension must be one of ".yaml", ".yml", ".json", ".env",
+// ".ini", ".properties", ".hcl", ".jsonc" or ".json5", the format of the
+// file without extension is detected by the contents, see MergeFromReader,
+// "-" means the stdin.
+//
+// The files in the "include" or "$import" directive are merged too, the value
+// is a path or a list of paths, the paths can be globs and the relative paths
+// are relative to the including file, see SetIncludeOrder for the merge order.
+func (c *Config) MergeFromFile(fpath string) error {
+	return c.mergeFromFile(osFileSystem, fpath, nil)
 }
 
 // MergeFromJSON  merges data from JSON bytes, the value from same name will be replaced.
@@ -143,10 +259,10 @@ func (c *Config) KV() map[string]interface{} {
 
 // Has returns true if the name has a value, otherwise false.
 func (c *Config) Has(name string) bool {
-	if _, in := c.flags[name]; in {
+	if _, in := c.lookupFlag(name); in {
 		return true
 	}
-	if env := os.Getenv(name); env != "" {
+	if env := c.getenv(name); env != "" {
 		return true
 	}
 	_, in := c.kv[name]
@@ -160,7 +276,7 @@ func (c *Config) Must(name string) {
 	}
 }
 
-// Raw returns the raw value by name.
+// Raw returns the raw value by name, the references are not resolved.
 // Excludes the flags and environment variable.
 func (c *Config) Raw(name string) interface{} {
 	return c.kv[name]
@@ -169,18 +285,24 @@ func (c *Config) Raw(name string) interface{} {
 // Value returns a Valuer by name.
 // Excludes the flags and environment variable.
 func (c *Config) Value(name string) Valuer {
-	v, ok := c.kv[name]
+	v, ok := c.lookup(name)
 	if !ok {
 		return NewValue(nil)
 	}
 	if child, ok := v.(Configer); ok {
-		return NewValue(child.KV())
+		v = child.KV()
 	}
-	return NewValue(v)
+	return &Value{v: v, coercion: c.coercionOf(name)}
 }
 
-// Pattern returns a Patterner by name.
+// Pattern returns a Patterner by name, if the value is a list,
+// all the patterns in it must be valid, see All.
 func (c *Config) Pattern(name string) Patterner {
+	v, _ := c.lookup(name)
+	switch x := v.(type) {
+	case []interface{}, map[string]interface{}, map[interface{}]interface{}:
+		return newPatternFrom(x)
+	}
 	return NewPattern(c.String(name))
 }
 
@@ -205,36 +327,51 @@ func (c *Config) Config(name string) Configer {
 			return x
 		case map[string]interface{}:
 			child := newConfigFrom(x)
+			child.root = c.rootConfig()
 			c.Set(name, child)
 			return child
 		case map[interface{}]interface{}:
 			child := newConfig()
 			child.kv = unknownMapToStringMap(x)
+			child.root = c.rootConfig()
 			c.Set(name, child)
 			return child
+		case string:
+			// The reference is kept, so the child is a copy.
+			if hasReference(x) {
+				resolved, _ := c.lookup(name)
+				return (&Value{v: resolved, coercion: c.coercionPolicy()}).Config()
+			}
 		default:
 		}
 	}
 	child := newConfig()
+	child.root = c.rootConfig()
 	c.Set(name, child)
 	return child
 }
 
-// String returns the string value by name, returns "" if not found.
+// String returns the string value by name, returns "" if not found, see StringOr
+// for the secret references.
 func (c *Config) String(name string) string {
 	return c.StringOr(name, "")
 }
 
 // StringOr returns the string value by name, returns the deflt if not found.
+// The secret references are resolved and revealed, the deflt is returned if
+// they can not be resolved, use SecretE to get the error.
 func (c *Config) StringOr(name string, deflt string) string {
-	if v, ok := c.flags[name].(string); ok && v != "" {
+	if v, ok := c.flag(name).(string); ok && v != "" {
 		return v
 	}
-	if env := os.Getenv(name); env != "" {
+	if env := c.getenv(name); env != "" {
 		return env
 	}
-	if v, in := c.kv[name]; in {
-		return toString(v, deflt)
+	if v, in := c.lookup(name); in {
+		if secret, ok := v.(Secret); ok {
+			return secret.Reveal()
+		}
+		return toString(c.coercionPolicy().text(v), deflt)
 	}
 	return deflt
 }
@@ -268,14 +405,17 @@ func (c *Config) Bool(name string) bool {
 
 // BoolOr returns the bool value by name, returns the deflt if not found.
 func (c *Config) BoolOr(name string, deflt bool) bool {
-	if v, ok := c.flags[name].(bool); ok && v != false {
+	if v, ok := c.flag(name).(bool); ok && v != false {
 		return v
 	}
-	if env := os.Getenv(name); env != "" {
+	if env := c.getenv(name); env != "" {
+		if b, ok := c.coercionPolicy().boolean(env).(bool); ok {
+			return b
+		}
 		return true
 	}
-	if v, exists := c.kv[name]; exists {
-		return toBool(v, deflt)
+	if v, exists := c.lookup(name); exists {
+		return toBool(c.coercionOf(name).boolean(v), deflt)
 	}
 	return deflt
 }
@@ -287,16 +427,16 @@ func (c *Config) Int(name string) int {
 
 // IntOr returns the int value by name, returns the deflt if not found.
 func (c *Config) IntOr(name string, deflt int) int {
-	if v, ok := c.flags[name].(int); ok && v != 0 {
+	if v, ok := c.flag(name).(int); ok && v != 0 {
 		return v
 	}
-	if env := os.Getenv(name); env != "" {
+	if env := c.getenv(name); env != "" {
 		if n, err := strconv.Atoi(env); err == nil {
 			return n
 		}
 	}
-	if v, exists := c.kv[name]; exists {
-		return toInt(v, deflt)
+	if v, exists := c.lookup(name); exists {
+		return toInt(c.coercionOf(name).numeric(v), deflt)
 	}
 	return deflt
 }
@@ -330,16 +470,16 @@ func (c *Config) Int64(name string) int64 {
 
 // Int64Or returns the int64 value by name, returns the deflt if not found.
 func (c *Config) Int64Or(name string, deflt int64) int64 {
-	if v, ok := c.flags[name].(int64); ok && v != int64(0) {
+	if v, ok := asInt64(c.flag(name)); ok && v != int64(0) {
 		return v
 	}
-	if env := os.Getenv(name); env != "" {
+	if env := c.getenv(name); env != "" {
 		if n, err := strconv.ParseInt(env, 10, 64); err == nil {
 			return n
 		}
 	}
-	if v, exists := c.kv[name]; exists {
-		return toInt64(v, deflt)
+	if v, exists := c.lookup(name); exists {
+		return toInt64(c.coercionOf(name).numeric(v), deflt)
 	}
 	return deflt
 }
@@ -375,16 +515,16 @@ func (c *Config) Float(name string) float64 {
 
 // FloatOr returns the float64 value by name, return deflt if not found.
 func (c *Config) FloatOr(name string, deflt float64) float64 {
-	if v, ok := c.flags[name].(float64); ok && v != float64(0) {
+	if v, ok := c.flag(name).(float64); ok && v != float64(0) {
 		return v
 	}
-	if env := os.Getenv(name); env != "" {
+	if env := c.getenv(name); env != "" {
 		if n, err := strconv.ParseFloat(env, 64); err == nil {
 			return n
 		}
 	}
-	if v, exists := c.kv[name]; exists {
-		return toFloat64(v, deflt)
+	if v, exists := c.lookup(name); exists {
+		return toFloat64(c.coercionOf(name).numeric(v), deflt)
 	}
 	return deflt
 }
@@ -411,6 +551,210 @@ func (c *Config) FloatAndOr(name string, pattern string, deflt float64) float64
 	return deflt
 }
 
+// Uint returns the uint value by name, returns 0 if not found.
+func (c *Config) Uint(name string) uint {
+	return c.UintOr(name, 0)
+}
+
+// UintOr returns the uint value by name, returns the deflt if not found
+// or overflows.
+func (c *Config) UintOr(name string, deflt uint) uint {
+	if v, ok := c.number(name); ok {
+		return toUint(v, deflt)
+	}
+	return deflt
+}
+
+// UintE returns the uint value by name, returns an error if not found,
+// not a number or overflows.
+func (c *Config) UintE(name string) (uint, error) {
+	v, err := c.numberE(name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := toUint64E(v, math.MaxUint, "uint")
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", name, err)
+	}
+	return uint(n), nil
+}
+
+// UintAnd returns the (uint value, true) by name if pattern matched,
+// otherwise returns (0, false). NOTE: we convert all numbers into
+// float64 then validate.
+func (c *Config) UintAnd(name string, pattern string) (uint, bool) {
+	n, err := c.UintE(name)
+	if err != nil {
+		return 0, false
+	}
+	if p := NewPattern(pattern); p.ValidateFloat(float64(n)) {
+		return n, true
+	}
+	return 0, false
+}
+
+// UintAndOr returns the uint value by name if pattern matched,
+// otherwise returns the deflt. NOTE: we convert all numbers into
+// float64 then validate.
+func (c *Config) UintAndOr(name string, pattern string, deflt uint) uint {
+	if n, ok := c.UintAnd(name, pattern); ok {
+		return n
+	}
+	return deflt
+}
+
+// Uint64 returns the uint64 value by name, returns 0 if not found.
+func (c *Config) Uint64(name string) uint64 {
+	return c.Uint64Or(name, 0)
+}
+
+// Uint64Or returns the uint64 value by name, returns the deflt if not found
+// or overflows.
+func (c *Config) Uint64Or(name string, deflt uint64) uint64 {
+	if v, ok := c.number(name); ok {
+		return toUint64(v, deflt)
+	}
+	return deflt
+}
+
+// Uint64E returns the uint64 value by name, returns an error if not found,
+// not a number or overflows.
+func (c *Config) Uint64E(name string) (uint64, error) {
+	v, err := c.numberE(name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := toUint64E(v, math.MaxUint64, "uint64")
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", name, err)
+	}
+	return n, nil
+}
+
+// Uint64And returns the (uint64 value, true) by name if pattern matched,
+// otherwise returns (0, false). NOTE: we convert all numbers into
+// float64 then validate.
+func (c *Config) Uint64And(name string, pattern string) (uint64, bool) {
+	n, err := c.Uint64E(name)
+	if err != nil {
+		return 0, false
+	}
+	if p := NewPattern(pattern); p.ValidateFloat(float64(n)) {
+		return n, true
+	}
+	return 0, false
+}
+
+// Uint64AndOr returns the uint64 value by name if pattern matched,
+// otherwise returns the deflt. NOTE: we convert all numbers into
+// float64 then validate.
+func (c *Config) Uint64AndOr(name string, pattern string, deflt uint64) uint64 {
+	if n, ok := c.Uint64And(name, pattern); ok {
+		return n
+	}
+	return deflt
+}
+
+// Int32 returns the int32 value by name, returns 0 if not found.
+func (c *Config) Int32(name string) int32 {
+	return c.Int32Or(name, 0)
+}
+
+// Int32Or returns the int32 value by name, returns the deflt if not found
+// or overflows.
+func (c *Config) Int32Or(name string, deflt int32) int32 {
+	if v, ok := c.number(name); ok {
+		return toInt32(v, deflt)
+	}
+	return deflt
+}
+
+// Int32E returns the int32 value by name, returns an error if not found,
+// not a number or overflows.
+func (c *Config) Int32E(name string) (int32, error) {
+	v, err := c.numberE(name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := toInt64E(v, math.MinInt32, math.MaxInt32, "int32")
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", name, err)
+	}
+	return int32(n), nil
+}
+
+// Int32And returns the (int32 value, true) by name if pattern matched,
+// otherwise returns (0, false).
+func (c *Config) Int32And(name string, pattern string) (int32, bool) {
+	n, err := c.Int32E(name)
+	if err != nil {
+		return 0, false
+	}
+	if p := NewPattern(pattern); p.ValidateInt(int(n)) {
+		return n, true
+	}
+	return 0, false
+}
+
+// Int32AndOr returns the int32 value by name if pattern matched,
+// otherwise returns the deflt.
+func (c *Config) Int32AndOr(name string, pattern string, deflt int32) int32 {
+	if n, ok := c.Int32And(name, pattern); ok {
+		return n
+	}
+	return deflt
+}
+
+// Float32 returns the float32 value by name, returns 0.0 if not found.
+func (c *Config) Float32(name string) float32 {
+	return c.Float32Or(name, 0.0)
+}
+
+// Float32Or returns the float32 value by name, returns the deflt if not found
+// or overflows.
+func (c *Config) Float32Or(name string, deflt float32) float32 {
+	if v, ok := c.number(name); ok {
+		return toFloat32(v, deflt)
+	}
+	return deflt
+}
+
+// Float32E returns the float32 value by name, returns an error if not found,
+// not a number or overflows.
+func (c *Config) Float32E(name string) (float32, error) {
+	v, err := c.numberE(name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := toFloat32E(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", name, err)
+	}
+	return n, nil
+}
+
+// Float32And returns the (float32 value, true) by name if pattern matched,
+// otherwise returns (0.0, false).
+func (c *Config) Float32And(name string, pattern string) (float32, bool) {
+	n, err := c.Float32E(name)
+	if err != nil {
+		return 0.0, false
+	}
+	if p := NewPattern(pattern); p.ValidateFloat(float64(n)) {
+		return n, true
+	}
+	return 0.0, false
+}
+
+// Float32AndOr returns the float32 value by name if pattern matched,
+// otherwise returns the deflt.
+func (c *Config) Float32AndOr(name string, pattern string, deflt float32) float32 {
+	if n, ok := c.Float32And(name, pattern); ok {
+		return n
+	}
+	return deflt
+}
+
 // Duration returns the time.Duration value by name,
 // return time.Duration(0) if not found.
 func (c *Config) Duration(name string) time.Duration {
@@ -420,20 +764,49 @@ func (c *Config) Duration(name string) time.Duration {
 // DurationOr returns the time.Duration value by name,
 // return time.Duration(deflt) if not found.
 func (c *Config) DurationOr(name string, deflt int64) time.Duration {
+	if d, ok := c.durationString(name); ok {
+		return d
+	}
 	return time.Duration(c.Int64Or(name, deflt))
 }
 
+// durationString parses the duration string by name from the environment
+// variable or the config data if lenient, the non-zero flag has priority.
+func (c *Config) durationString(name string) (time.Duration, bool) {
+	co := c.coercionOf(name)
+	if co != CoerceLenient {
+		return 0, false
+	}
+	if n, ok := asInt64(c.flag(name)); ok && n != 0 {
+		return 0, false
+	}
+	if env := c.getenv(name); env != "" {
+		return co.duration(env)
+	}
+	v, _ := c.lookup(name)
+	return co.duration(v)
+}
+
 // DurationAnd returns the (time.Duration(value), true) by name if pattern matched,
 // otherwise (time.Duration(0), false) returned. NOTE: we convert all numbers into
 // float64 then validate.
 func (c *Config) DurationAnd(name string, pattern string) (time.Duration, bool) {
-	n, ok := c.Int64And(name, pattern)
-	return time.Duration(n), ok
+	if !c.Has(name) {
+		return 0, false
+	}
+	p := NewPattern(pattern)
+	if d := c.Duration(name); p.ValidateFloat(float64(d)) {
+		return d, true
+	}
+	return 0, false
 }
 
 // DurationAndOr returns the time.Duration value by name if pattern matched,
 // otherwise returns the deflt. NOTE: we convert all numbers into
 // float64 then validate.
 func (c *Config) DurationAndOr(name string, pattern string, deflt int64) time.Duration {
-	return time.Duration(c.Int64AndOr(name, pattern, deflt))
+	if d, ok := c.DurationAnd(name, pattern); ok {
+		return d
+	}
+	return time.Duration(deflt)
 }
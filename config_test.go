@@ -2,6 +2,7 @@ package cc
 
 import (
 	"flag"
+	"math"
 	"os"
 	"testing"
 	"time"
@@ -292,3 +293,140 @@ func TestConfigGetDuration(t *testing.T) {
 	assert.Check(t, c.Duration("duration_flag"), time.Duration(6464))
 	assert.Check(t, c.DurationOr("duration_flag_default", 4646), time.Duration(4646))
 }
+
+func TestConfigEnvPrefix(t *testing.T) {
+	os.Setenv("MYAPP_DB_HOST", "env-host")
+	os.Setenv("MYAPP_PORT", "9090")
+	os.Setenv("CC_TEST_NAME", "bound")
+	defer os.Unsetenv("MYAPP_DB_HOST")
+	defer os.Unsetenv("MYAPP_PORT")
+	defer os.Unsetenv("CC_TEST_NAME")
+
+	c := NewConfigFrom(map[string]interface{}{
+		"db.host": "file-host",
+		"port":    8080,
+		"name":    "cc",
+	})
+	assert.Check(t, c.EnvName("port"), "port")
+	c.SetEnvPrefix("myapp")
+	c.BindEnv("name", "CC_TEST_NAME")
+	assert.Check(t, c.EnvName("db.host"), "MYAPP_DB_HOST")
+	assert.Check(t, c.EnvName("name"), "CC_TEST_NAME")
+	assert.Check(t, c.String("db.host"), "env-host")
+	assert.Check(t, c.Int("port"), 9090)
+	assert.Check(t, c.String("name"), "bound")
+}
+
+func TestConfigJSONNumber(t *testing.T) {
+	c, err := NewConfigFromJSON([]byte(`{
+		"id": 9007199254740993,
+		"timeout": 1000000000000000001,
+		"big": 18446744073709551615,
+		"huge": 1e300,
+		"float": 1.5,
+		"exp": 1e3,
+		"neg": -1
+	}`))
+	assert.Must(t, err)
+	assert.Check(t, c.Int64("id"), int64(9007199254740993))
+	assert.Check(t, c.Int("id"), 9007199254740993)
+	assert.Check(t, c.Duration("timeout"), time.Duration(1000000000000000001))
+	assert.Check(t, c.Int64Or("big", -1), int64(-1))
+	assert.Check(t, c.Int64Or("huge", -1), int64(-1))
+	assert.Check(t, c.Float("big"), 18446744073709551615.0)
+	assert.Check(t, c.Float("float"), 1.5)
+	assert.Check(t, c.Int("float"), 1)
+	assert.Check(t, c.Int("exp"), 1000)
+	n, ok := c.IntAnd("id", "N>0")
+	assert.Check(t, ok, true)
+	assert.Check(t, n, 9007199254740993)
+
+	var v struct {
+		ID   uint64 `cc:"id"`
+		Big  uint64 `cc:"big"`
+		Exp  int32  `cc:"exp"`
+		Huge int64  `cc:"huge"`
+	}
+	err = c.Decode(&v)
+	assert.Check(t, err.Error(), "huge: 1e300 overflows int64")
+	assert.Check(t, v.ID, uint64(9007199254740993))
+	assert.Check(t, v.Big, uint64(18446744073709551615))
+	assert.Check(t, v.Exp, int32(1000))
+
+	var neg struct {
+		Neg uint `cc:"neg"`
+	}
+	assert.Check(t, c.Decode(&neg).Error(), "neg: -1 overflows uint")
+
+	if _, err := NewConfigFromJSON([]byte(`{"a": 1} {}`)); err == nil {
+		t.Fatal("expect error for the trailing data")
+	}
+	_, err = NewConfigFromJSON([]byte(`{"a": {"b": [1, 1e400]}}`))
+	assert.Check(t, err.Error(), "a.b[1]: number 1e400 overflows float64")
+	assert.Check(t, c.MergeFromJSON([]byte(`{"huge": -1e400}`)).Error(), "huge: number -1e400 overflows float64")
+	assert.Check(t, c.Float("huge"), 1e300)
+}
+
+func TestConfigGetUint(t *testing.T) {
+	c := NewConfigFrom(map[string]interface{}{
+		"port":  8080,
+		"max":   uint64(math.MaxUint64),
+		"neg":   -1,
+		"ratio": 0.5,
+		"name":  "x",
+	})
+	assert.Check(t, c.Uint("port"), uint(8080))
+	assert.Check(t, c.UintOr("non", 1), uint(1))
+	assert.Check(t, c.Uint64("max"), uint64(math.MaxUint64))
+	assert.Check(t, c.Int64Or("max", -1), int64(-1))
+	assert.Check(t, c.UintOr("neg", 1), uint(1))
+	assert.Check(t, c.Int32("port"), int32(8080))
+	assert.Check(t, c.Float32("ratio"), float32(0.5))
+	assert.Check(t, c.UintAndOr("port", "N<65536", 1), uint(8080))
+	assert.Check(t, c.Uint64AndOr("port", "N>65536", 1), uint64(1))
+	assert.Check(t, c.Int32AndOr("port", "N>0", 1), int32(8080))
+	assert.Check(t, c.Float32AndOr("ratio", "N<1", 1), float32(0.5))
+	res, ok := c.UintAnd("non", "N>0")
+	assert.Check(t, ok, false)
+	assert.Check(t, res, uint(0))
+
+	for name, expected := range map[string]string{
+		"neg":  "neg: -1 overflows uint64",
+		"name": "name: expected uint64, got string",
+		"non":  "non: not found",
+	} {
+		_, err := c.Uint64E(name)
+		assert.Check(t, err.Error(), expected)
+	}
+	_, err := c.Int32E("max")
+	assert.Check(t, err.Error(), "max: 18446744073709551615 overflows int32")
+	_, err = c.UintE("ratio")
+	assert.Must(t, err)
+	_, err = c.Float32E("name")
+	assert.Check(t, err.Error(), "name: expected float32, got string")
+
+	os.Setenv("CC_TEST_UINT", "18446744073709551615")
+	defer os.Unsetenv("CC_TEST_UINT")
+	c.BindEnv("port", "CC_TEST_UINT")
+	assert.Check(t, c.Uint64("port"), uint64(math.MaxUint64))
+	assert.Check(t, c.Int32Or("port", 1), int32(1))
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Uint64("big", math.MaxUint64, "usage")
+	fs.Uint("small", 3, "usage")
+	fs.Uint("zero", 0, "usage")
+	assert.Must(t, fs.Parse(nil))
+	c.ParseFlagSet(fs)
+	assert.Check(t, c.Uint64("big"), uint64(math.MaxUint64))
+	assert.Check(t, c.Int64Or("big", -1), int64(-1))
+	assert.Check(t, c.Int64("small"), int64(3))
+	assert.Check(t, c.Uint("small"), uint(3))
+	c.Set("zero", 5)
+	assert.Check(t, c.Uint("zero"), uint(5))
+
+	var v struct {
+		Big uint64 `cc:"big"`
+	}
+	assert.Must(t, c.Decode(&v))
+	assert.Check(t, v.Big, uint64(math.MaxUint64))
+}
@@ -0,0 +1,345 @@
+package cc
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema declares the keys of a Config in Go, including the types, defaults,
+// patterns, docs and the bound environment variables and flags, e.g.
+//
+//	s := cc.NewSchema()
+//	s.Int("port", cc.Default(8080), cc.Validate("N>0&&N<65536"), cc.Doc("listen port"),
+//		cc.Env("PORT"), cc.Flag("port"))
+//	s.RegisterFlags(flag.CommandLine)
+//	flag.Parse()
+//
+//	c, _ := cc.NewConfigFromFile("config.yaml")
+//	s.Apply(c)
+//	if err := s.Check(c); err != nil {
+//		// ...
+//	}
+type Schema struct {
+	keys []*key
+}
+
+type key struct {
+	name     string
+	typ      string
+	deflt    interface{}
+	pattern  string
+	doc      string
+	env      string
+	flag     string
+	required bool
+}
+
+// KeyOption configures a key declared in Schema.
+type KeyOption func(k *key)
+
+// Default sets the default value of the key, it must have the declared type,
+// an int64 or time.Duration for Duration.
+func Default(value interface{}) KeyOption {
+	return func(k *key) {
+		k.deflt = value
+	}
+}
+
+// Validate sets the pattern of the key, see Patterner.
+func Validate(pattern string) KeyOption {
+	return func(k *key) {
+		k.pattern = pattern
+	}
+}
+
+// Doc sets the description of the key.
+func Doc(doc string) KeyOption {
+	return func(k *key) {
+		k.doc = doc
+	}
+}
+
+// Env binds the environment variable to the key.
+func Env(name string) KeyOption {
+	return func(k *key) {
+		k.env = name
+	}
+}
+
+// Flag binds the flag to the key.
+func Flag(name string) KeyOption {
+	return func(k *key) {
+		k.flag = name
+	}
+}
+
+// Required marks the key as required.
+func Required() KeyOption {
+	return func(k *key) {
+		k.required = true
+	}
+}
+
+// NewSchema creates a new empty Schema.
+func NewSchema() *Schema {
+	return &Schema{}
+}
+
+func (s *Schema) declare(name string, typ string, opts []KeyOption) *Schema {
+	k := &key{name: name, typ: typ}
+	for _, opt := range opts {
+		opt(k)
+	}
+	s.keys = append(s.keys, k)
+	return s
+}
+
+// String declares a string key.
+func (s *Schema) String(name string, opts ...KeyOption) *Schema {
+	return s.declare(name, "string", opts)
+}
+
+// Bool declares a bool key.
+func (s *Schema) Bool(name string, opts ...KeyOption) *Schema {
+	return s.declare(name, "bool", opts)
+}
+
+// Int declares an int key.
+func (s *Schema) Int(name string, opts ...KeyOption) *Schema {
+	return s.declare(name, "int", opts)
+}
+
+// Int64 declares an int64 key.
+func (s *Schema) Int64(name string, opts ...KeyOption) *Schema {
+	return s.declare(name, "int64", opts)
+}
+
+// Float declares a float64 key.
+func (s *Schema) Float(name string, opts ...KeyOption) *Schema {
+	return s.declare(name, "float", opts)
+}
+
+// Duration declares a time.Duration key.
+func (s *Schema) Duration(name string, opts ...KeyOption) *Schema {
+	return s.declare(name, "duration", opts)
+}
+
+// RegisterFlags defines the flags of the keys which have bound flags on fs.
+// The flags are defined with zero values, since the non-zero values in flags
+// always have the priority, the defaults are shown in the usages.
+func (s *Schema) RegisterFlags(fs *flag.FlagSet) {
+	for _, k := range s.keys {
+		if k.flag == "" || fs.Lookup(k.flag) != nil {
+			continue
+		}
+		usage := k.doc
+		if k.deflt != nil {
+			usage = strings.TrimSpace(fmt.Sprintf("%s (default %v)", usage, k.formatDefault()))
+		}
+		switch k.typ {
+		case "string":
+			fs.String(k.flag, "", usage)
+		case "bool":
+			fs.Bool(k.flag, false, usage)
+		case "int":
+			fs.Int(k.flag, 0, usage)
+		case "int64":
+			fs.Int64(k.flag, 0, usage)
+		case "float":
+			fs.Float64(k.flag, 0, usage)
+		case "duration":
+			fs.Duration(k.flag, 0, usage)
+		}
+	}
+}
+
+// Apply sets the defaults and binds the environment variables and
+// flags of the keys to the Config.
+func (s *Schema) Apply(c *Config) {
+	for _, k := range s.keys {
+		if k.deflt != nil {
+			deflt := k.deflt
+			if d, ok := deflt.(time.Duration); ok {
+				deflt = int64(d)
+			}
+			c.SetDefault(k.name, deflt)
+		}
+		if k.env != "" {
+			c.BindEnv(k.name, k.env)
+		}
+		if k.flag != "" {
+			c.BindFlag(k.name, k.flag)
+		}
+	}
+}
+
+// Check checks the Config against the Schema, it returns SchemaErrors which
+// contains all the missing required keys, the values with wrong types and
+// the values which do not match the patterns. The values are converted as the
+// getters do, see SetCoercion.
+func (s *Schema) Check(c *Config) error {
+	var errs SchemaErrors
+	for _, k := range s.keys {
+		path := "/" + escapePointer(k.name)
+		if !c.Has(k.name) {
+			if k.required {
+				errs = append(errs, &SchemaError{Path: path, Message: "missing required key"})
+			}
+			continue
+		}
+		if v, in := c.lookup(k.name); in && !k.isType(v, c.coercionOf(k.name)) {
+			errs = append(errs, &SchemaError{Path: path, Message: fmt.Sprintf("expected %s, got %s", k.typ, typeOf(normalize(v)))})
+			continue
+		}
+		if k.pattern == "" {
+			continue
+		}
+		p := NewPattern(k.pattern)
+		var err error
+		switch k.typ {
+		case "string":
+			err = p.ValidateStringE(c.String(k.name))
+		case "int":
+			err = p.ValidateIntE(c.Int(k.name))
+		case "int64":
+			err = p.ValidateFloatE(float64(c.Int64(k.name)))
+		case "duration":
+			err = p.ValidateFloatE(float64(c.Duration(k.name)))
+		case "float":
+			err = p.ValidateFloatE(c.Float(k.name))
+		}
+		if err != nil {
+			errs = append(errs, &SchemaError{Path: path, Message: err.Error()})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// isType reports whether v is the type of the key, converted by co as the
+// getters do, e.g. "8080" is an int and "3s" is a duration if lenient.
+func (k *key) isType(v interface{}, co Coercion) bool {
+	switch k.typ {
+	case "string":
+		_, ok := co.text(v).(string)
+		return ok
+	case "bool":
+		_, ok := co.boolean(v).(bool)
+		return ok
+	case "duration":
+		if _, ok := co.duration(v); ok {
+			return true
+		}
+		return isType(co.numeric(v), "integer")
+	case "int", "int64":
+		return isType(co.numeric(v), "integer")
+	case "float":
+		return isType(co.numeric(v), "number")
+	}
+	return false
+}
+
+// zero returns the default value, or the zero value of the type.
+func (k *key) zero() interface{} {
+	if k.deflt != nil {
+		if d, ok := k.deflt.(time.Duration); ok {
+			return int64(d)
+		}
+		return k.deflt
+	}
+	switch k.typ {
+	case "string":
+		return ""
+	case "bool":
+		return false
+	case "float":
+		return 0.0
+	}
+	return 0
+}
+
+func (k *key) formatDefault() string {
+	if d, ok := k.deflt.(time.Duration); ok {
+		return d.String()
+	}
+	if str, ok := k.deflt.(string); ok {
+		return strconv.Quote(str)
+	}
+	return fmt.Sprintf("%v", k.deflt)
+}
+
+// SampleYAML generates a sample YAML config, the docs are written as comments.
+func (s *Schema) SampleYAML() []byte {
+	var buf bytes.Buffer
+	for i, k := range s.keys {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		if k.doc != "" {
+			for _, line := range strings.Split(k.doc, "\n") {
+				fmt.Fprintf(&buf, "# %s\n", line)
+			}
+		}
+		if k.pattern != "" {
+			fmt.Fprintf(&buf, "# pattern: %s\n", k.pattern)
+		}
+		v, _ := json.Marshal(k.zero()) // JSON scalars are valid YAML
+		fmt.Fprintf(&buf, "%s: %s\n", k.name, v)
+	}
+	return buf.Bytes()
+}
+
+// SampleJSON generates a sample JSON config, the keys are in declaration order.
+func (s *Schema) SampleJSON() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for i, k := range s.keys {
+		name, _ := json.Marshal(k.name)
+		v, _ := json.Marshal(k.zero())
+		fmt.Fprintf(&buf, "    %s: %s", name, v)
+		if i < len(s.keys)-1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// Markdown generates the reference of the keys as a Markdown table.
+func (s *Schema) Markdown() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("| Key | Type | Default | Env | Flag | Pattern | Description |\n")
+	buf.WriteString("|-----|------|---------|-----|------|---------|-------------|\n")
+	code := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "`" + strings.Replace(s, "|", `\|`, -1) + "`"
+	}
+	for _, k := range s.keys {
+		deflt := ""
+		if k.deflt != nil {
+			deflt = code(k.formatDefault())
+		}
+		flagName := ""
+		if k.flag != "" {
+			flagName = code("-" + k.flag)
+		}
+		name := code(k.name)
+		if k.required {
+			name += " (required)"
+		}
+		doc := strings.Replace(strings.Replace(k.doc, "|", `\|`, -1), "\n", " ", -1)
+		fmt.Fprintf(&buf, "| %s | %s | %s | %s | %s | %s | %s |\n",
+			name, k.typ, deflt, code(k.env), flagName, code(k.pattern), doc)
+	}
+	return buf.Bytes()
+}
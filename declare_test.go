@@ -0,0 +1,130 @@
+package cc
+
+import (
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/damnever/cc/assert"
+)
+
+func newTestSchema() *Schema {
+	s := NewSchema()
+	s.Int("port", Default(8080), Validate("N>0&&N<65536"), Doc("listen port"), Env("TEST_PORT"), Flag("port"))
+	s.String("level", Default("info"), Validate("enum:debug|info|warn"), Doc("log level"), Flag("log-level"))
+	s.String("name", Required(), Doc("service name"))
+	s.Duration("timeout", Default(3*time.Second))
+	s.Float("ratio", Validate("N in [0, 1]"))
+	return s
+}
+
+func TestSchemaApply(t *testing.T) {
+	s := newTestSchema()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	s.RegisterFlags(fs)
+	assert.Check(t, fs.Lookup("port").Usage, "listen port (default 8080)")
+	assert.Check(t, fs.Lookup("log-level").Usage, `log level (default "info")`)
+	assert.Must(t, fs.Parse([]string{"-log-level", "debug"}))
+
+	c := NewConfig()
+	c.ParseFlagSet(fs)
+	s.Apply(c)
+	assert.Check(t, c.Int("port"), 8080)
+	assert.Check(t, c.String("level"), "debug")
+	assert.Check(t, c.Duration("timeout"), 3*time.Second)
+
+	os.Setenv("TEST_PORT", "9090")
+	defer os.Unsetenv("TEST_PORT")
+	assert.Check(t, c.Int("port"), 9090)
+
+	assert.Must(t, fs.Parse([]string{"-port", "7070"}))
+	c.ParseFlagSet(fs)
+	assert.Check(t, c.Int("port"), 7070)
+}
+
+func TestSchemaCheck(t *testing.T) {
+	s := newTestSchema()
+	c := NewConfig()
+	s.Apply(c)
+	err := s.Check(c)
+	errs, ok := err.(SchemaErrors)
+	if !ok {
+		t.Fatalf("expect SchemaErrors, got %v", err)
+	}
+	assert.Check(t, len(errs), 1)
+	assert.Check(t, errs[0].Error(), "/name: missing required key")
+
+	c.Set("name", "cc")
+	c.Set("port", 70000)
+	c.Set("level", "error")
+	c.Set("ratio", "0.5")
+	errs = s.Check(c).(SchemaErrors)
+	expected := []string{
+		"/port: 70000 does not satisfy N > 0 && N < 65536",
+		`/level: "error" does not match enum:debug|info|warn`,
+		"/ratio: expected float, got string",
+	}
+	assert.Check(t, len(errs), len(expected))
+	for i, e := range errs {
+		assert.Check(t, e.Error(), expected[i])
+	}
+
+	c.Set("port", 80)
+	c.Set("level", "warn")
+	c.Set("ratio", 1)
+	assert.Must(t, s.Check(c))
+
+	c.Set("port", "8080")
+	c.Set("timeout", "3s")
+	c.Set("ratio", "0.5")
+	errs = s.Check(c).(SchemaErrors)
+	assert.Check(t, len(errs), 3)
+	assert.Check(t, errs[0].Error(), "/port: expected int, got string")
+	c.SetCoercion(CoerceLenient)
+	assert.Must(t, s.Check(c))
+	c.Set("port", "80000")
+	c.Set("timeout", "3x")
+	errs = s.Check(c).(SchemaErrors)
+	assert.Check(t, len(errs), 2)
+	assert.Check(t, errs[0].Error(), "/port: 80000 does not satisfy N > 0 && N < 65536")
+	assert.Check(t, errs[1].Error(), "/timeout: expected duration, got string")
+}
+
+func TestSchemaGenerate(t *testing.T) {
+	s := newTestSchema()
+
+	yamlSample := string(s.SampleYAML())
+	assert.Check(t, yamlSample, `# listen port
+# pattern: N>0&&N<65536
+port: 8080
+
+# log level
+# pattern: enum:debug|info|warn
+level: "info"
+
+# service name
+name: ""
+
+timeout: 3000000000
+
+# pattern: N in [0, 1]
+ratio: 0
+`)
+	c, err := NewConfigFromYAML(s.SampleYAML())
+	assert.Must(t, err)
+	assert.Check(t, c.Duration("timeout"), 3*time.Second)
+
+	c, err = NewConfigFromJSON(s.SampleJSON())
+	assert.Must(t, err)
+	assert.Check(t, c.Int("port"), 8080)
+	assert.Check(t, c.String("level"), "info")
+
+	assert.Check(t, string(s.Markdown()), "| Key | Type | Default | Env | Flag | Pattern | Description |\n"+
+		"|-----|------|---------|-----|------|---------|-------------|\n"+
+		"| `port` | int | `8080` | `TEST_PORT` | `-port` | `N>0&&N<65536` | listen port |\n"+
+		"| `level` | string | `\"info\"` |  | `-log-level` | `enum:debug\\|info\\|warn` | log level |\n"+
+		"| `name` (required) | string |  |  |  |  | service name |\n"+
+		"| `timeout` | duration | `3s` |  |  |  |  |\n"+
+		"| `ratio` | float |  |  |  | `N in [0, 1]` |  |\n")
+}
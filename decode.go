@@ -0,0 +1,351 @@
+package cc
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	durationType  = reflect.TypeOf(time.Duration(0))
+	secretType    = reflect.TypeOf(Secret(""))
+	patternerType = reflect.TypeOf((*Patterner)(nil)).Elem()
+
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Decode decodes the Config into the struct pointed to by v.
+//
+// The key of a field is given by the `cc:"name"` tag, or the lower-cased
+// field name if no tag, `cc:"-"` means ignore. The fields without values
+// are left untouched, so they can be initialized as defaults. The flags and
+// environment variables are used for the top level fields.
+//
+// The string, bool, numbers, time.Duration(integer nanoseconds or string
+// like "3s"), Secret, structs, pointers, slices, maps with string keys,
+// Patterner and interface{} fields are supported. The numbers are checked for
+// overflow.
+//
+// The fields implementing Unmarshaler or encoding.TextUnmarshaler(from the
+// string values, e.g. time.Time and net.IP) are decoded by themselves, the
+// errors are prefixed by the field path too.
+//
+// The `pattern:"N>0"` tag validates the string and number fields, see Patterner.
+func (c *Config) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("can not decode into %T, expected a pointer to struct", v)
+	}
+	return decodeConfig(c, rv.Elem(), "")
+}
+
+// As decodes the value into the target pointed to by target, the same as the
+// fields in Config.Decode, so the Unmarshaler and encoding.TextUnmarshaler
+// are honored too, returns an error if not exists or mismatched:
+//
+//	var level LogLevel
+//	err := c.Value("level").As(&level)
+func (v *Value) As(target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("can not decode into %T, expected a non-nil pointer", target)
+	}
+	if !v.Exist() {
+		return errNotExist
+	}
+	return decodeValue(v, rv.Elem(), "")
+}
+
+func decodeConfig(c Configer, rv reflect.Value, path string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		name := f.Tag.Get("cc")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		if !c.Has(name) {
+			continue
+		}
+
+		fpath := joinPath(path, name)
+		if err := decodeValue(configValue(c, name, f.Type), rv.Field(i), fpath); err != nil {
+			return err
+		}
+		if pattern := f.Tag.Get("pattern"); pattern != "" {
+			if err := validateField(NewPattern(pattern), rv.Field(i), fpath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// configValue returns the value by name, the flags and environment variables
+// are used if they are set and the type is a scalar or a slice.
+func configValue(c Configer, name string, typ reflect.Type) Valuer {
+	config, ok := c.(*Config)
+	if !ok || !config.overridden(name) {
+		return c.Value(name)
+	}
+	switch {
+	case unmarshals(typ):
+		if v, ok := config.lookupFlag(name); ok && v != nil && !reflect.ValueOf(v).IsZero() {
+			return &Value{v: v, coercion: config.coercionPolicy()}
+		}
+		return &Value{v: config.getenv(name), coercion: config.coercionPolicy()}
+	case typ == secretType:
+		return NewValue(config.Secret(name))
+	case typ == durationType:
+		return NewValue(config.Int64(name))
+	case typ.Kind() == reflect.String:
+		return NewValue(config.String(name))
+	case typ.Kind() == reflect.Bool:
+		return NewValue(config.Bool(name))
+	case typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Int64:
+		return NewValue(config.Int64(name))
+	case typ.Kind() >= reflect.Uint && typ.Kind() <= reflect.Uint64:
+		return NewValue(config.Uint64(name))
+	case typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64:
+		return NewValue(config.Float(name))
+	case typ.Kind() == reflect.Slice:
+		val := config.sliceValue(name)
+		if list, ok := val.list(); ok {
+			return &Value{v: list, coercion: val.coercion}
+		}
+	}
+	return c.Value(name)
+}
+
+// unmarshals reports whether the typ decodes itself.
+func unmarshals(typ reflect.Type) bool {
+	for _, t := range []reflect.Type{typ, reflect.PtrTo(typ)} {
+		if t.Implements(unmarshalerType) || t.Implements(textUnmarshalerType) {
+			return true
+		}
+	}
+	return false
+}
+
+// overridden reports whether the value by name is set by flags or
+// environment variables.
+func (c *Config) overridden(name string) bool {
+	if env := c.getenv(name); env != "" {
+		return true
+	}
+	v, ok := c.lookupFlag(name)
+	return ok && v != nil && !reflect.ValueOf(v).IsZero()
+}
+
+func decodeValue(val Valuer, rv reflect.Value, path string) error {
+	raw := val.Raw()
+	if raw == nil {
+		return nil
+	}
+	mismatch := func(expected string) error {
+		return decodeError(path, "expected %s, got %T", expected, raw)
+	}
+
+	rt := rv.Type()
+	if rv.CanAddr() {
+		switch u := rv.Addr().Interface().(type) {
+		case Unmarshaler:
+			if err := u.UnmarshalCC(val); err != nil {
+				return decodeError(path, "%v", err)
+			}
+			return nil
+		case encoding.TextUnmarshaler:
+			if v, ok := val.(*Value); ok {
+				raw = v.coercion.text(raw)
+			}
+			s, ok := raw.(string)
+			if !ok {
+				return mismatch("string")
+			}
+			if err := u.UnmarshalText([]byte(s)); err != nil {
+				return decodeError(path, "%v", err)
+			}
+			return nil
+		}
+	}
+	if v, ok := val.(*Value); ok {
+		raw = v.coercion.kind(raw, rt.Kind())
+	}
+	switch {
+	case rt == patternerType:
+		p := val.Pattern()
+		if err := p.Err(); err != nil {
+			return decodeError(path, "%v", err)
+		}
+		rv.Set(reflect.ValueOf(p))
+		return nil
+	case rt == secretType:
+		secret, err := toSecretE(raw)
+		if err != nil {
+			if _, ok := raw.(string); ok {
+				return decodeError(path, "%v", err)
+			}
+			return mismatch("secret")
+		}
+		rv.SetString(string(secret))
+		return nil
+	case rt == durationType:
+		if s, ok := raw.(string); ok {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return decodeError(path, "%v", err)
+			}
+			rv.SetInt(int64(d))
+			return nil
+		}
+		if !isType(raw, "integer") {
+			return mismatch("duration")
+		}
+		n, ok := asInt64(raw)
+		if !ok {
+			return decodeError(path, "%v overflows %s", raw, rt)
+		}
+		rv.SetInt(n)
+		return nil
+	}
+
+	switch rt.Kind() {
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return mismatch("string")
+		}
+		rv.SetString(s)
+	case reflect.Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			return mismatch("bool")
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !isType(raw, "integer") {
+			return mismatch("integer")
+		}
+		n, ok := asInt64(raw)
+		if !ok || rv.OverflowInt(n) {
+			return decodeError(path, "%v overflows %s", raw, rt)
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !isType(raw, "integer") {
+			return mismatch("integer")
+		}
+		n, ok := asUint64(raw)
+		if !ok || rv.OverflowUint(n) {
+			return decodeError(path, "%v overflows %s", raw, rt)
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if !isType(raw, "number") {
+			return mismatch("number")
+		}
+		n := val.Float()
+		if rv.OverflowFloat(n) {
+			return decodeError(path, "%v overflows %s", n, rt)
+		}
+		rv.SetFloat(n)
+	case reflect.Struct:
+		if !isMap(raw) {
+			return mismatch("map")
+		}
+		return decodeConfig(val.Config(), rv, path)
+	case reflect.Ptr:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rt.Elem()))
+		}
+		return decodeValue(val, rv.Elem(), path)
+	case reflect.Slice:
+		if _, ok := raw.([]interface{}); !ok {
+			return mismatch("list")
+		}
+		list := val.List()
+		slice := reflect.MakeSlice(rt, len(list), len(list))
+		for i, e := range list {
+			if err := decodeValue(e, slice.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+	case reflect.Map:
+		if rt.Key().Kind() != reflect.String {
+			return decodeError(path, "unsupported map key type %s", rt.Key())
+		}
+		if !isMap(raw) {
+			return mismatch("map")
+		}
+		m := reflect.MakeMap(rt)
+		for k, e := range val.Map() {
+			ev := reflect.New(rt.Elem()).Elem()
+			if err := decodeValue(e, ev, joinPath(path, k)); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(k).Convert(rt.Key()), ev)
+		}
+		rv.Set(m)
+	case reflect.Interface:
+		if rt.NumMethod() != 0 {
+			return decodeError(path, "unsupported type %s", rt)
+		}
+		rv.Set(reflect.ValueOf(normalize(raw)))
+	default:
+		return decodeError(path, "unsupported type %s", rt)
+	}
+	return nil
+}
+
+// decodeError formats the error with the path as prefix if any.
+func decodeError(path string, format string, args ...interface{}) error {
+	if path == "" {
+		return fmt.Errorf(format, args...)
+	}
+	return fmt.Errorf("%s: "+format, append([]interface{}{path}, args...)...)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func isMap(v interface{}) bool {
+	switch v.(type) {
+	case Configer, map[string]interface{}, map[interface{}]interface{}:
+		return true
+	}
+	return false
+}
+
+func validateField(p Patterner, rv reflect.Value, path string) error {
+	var err error
+	switch rv.Kind() {
+	case reflect.String:
+		err = validateStringE(p, rv.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		err = validateFloatE(p, float64(rv.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		err = validateFloatE(p, float64(rv.Uint()))
+	case reflect.Float32, reflect.Float64:
+		err = validateFloatE(p, rv.Float())
+	default:
+		return decodeError(path, "pattern is not supported for %s", rv.Type())
+	}
+	if err != nil {
+		return decodeError(path, "%v", err)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package cc
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/damnever/cc/assert"
+)
+
+type testChild struct {
+	KeyThree int    `cc:"key_three" pattern:"N>30"`
+	KeyFour  string `cc:"key_four" pattern:"enum:good|bad"`
+}
+
+type testMap struct {
+	KeyOne bool       `cc:"key_one"`
+	KeyTwo bool       `cc:"key_two"`
+	Child  *testChild `cc:"child"`
+}
+
+type testPatterns struct {
+	String Patterner `cc:"string_pattern"`
+	Int    Patterner `cc:"int_pattern"`
+	Float  Patterner `cc:"float_pattern"`
+}
+
+type testConfig struct {
+	Name     string        `cc:"name"`
+	Map      testMap       `cc:"map"`
+	List     []interface{} `cc:"list"`
+	Patterns testPatterns  `cc:"patterns"`
+	Timeout  time.Duration `cc:"timeout"`
+	Port     uint16
+	Ignored  string `cc:"-"`
+	private  string
+}
+
+func TestConfigDecode(t *testing.T) {
+	c, err := NewConfigFromFile("./example/example.yaml")
+	assert.Must(t, err)
+	c.Set("timeout", "3s")
+	c.Set("port", 8080)
+	c.Set("-", "x")
+
+	v := testConfig{Name: "default", Ignored: "ignored"}
+	assert.Must(t, c.Decode(&v))
+	assert.Check(t, v.Name, "cc")
+	assert.Check(t, v.Map.KeyOne, true)
+	assert.Check(t, v.Map.Child.KeyThree, 33)
+	assert.Check(t, v.Map.Child.KeyFour, "good")
+	assert.Check(t, len(v.List), 4)
+	assert.Check(t, v.List[1], 2)
+	assert.Check(t, v.Patterns.String.ValidateString("aaaaa"), true)
+	assert.Check(t, v.Patterns.Int.ValidateInt(3), true)
+	assert.Check(t, v.Timeout, 3*time.Second)
+	assert.Check(t, v.Port, uint16(8080))
+	assert.Check(t, v.Ignored, "ignored")
+
+	os.Setenv("name", "env")
+	defer os.Unsetenv("name")
+	assert.Must(t, c.Decode(&v))
+	assert.Check(t, v.Name, "env")
+
+	c, err = NewConfigFromFile("./example/example.json")
+	assert.Must(t, err)
+	v = testConfig{}
+	assert.Must(t, c.Decode(&v))
+	assert.Check(t, v.Map.Child.KeyThree, 33)
+}
+
+func TestConfigDecodeErrors(t *testing.T) {
+	cases := map[string]map[string]interface{}{
+		"port: 70000 overflows uint16":                         {"port": 70000},
+		"port: -1 overflows uint16":                            {"port": -1},
+		"name: expected string, got int":                       {"name": 1},
+		"map: expected map, got string":                        {"map": "x"},
+		"map.child.key_three: expected integer, got float64":   {"map": map[string]interface{}{"child": map[string]interface{}{"key_three": 3.5}}},
+		"map.child.key_three: 3 does not satisfy N > 30":       {"map": map[string]interface{}{"child": map[string]interface{}{"key_three": 3}}},
+		`map.child.key_four: "x" does not match enum:good|bad`: {"map": map[interface{}]interface{}{"child": map[interface{}]interface{}{"key_four": "x"}}},
+		"list: expected list, got string":                      {"list": "x"},
+		"timeout: time: invalid duration \"x\"":                {"timeout": "x"},
+	}
+	for expected, kv := range cases {
+		err := NewConfigFrom(kv).Decode(&testConfig{})
+		if err == nil {
+			t.Fatalf("expect error %q, got nothing", expected)
+		}
+		assert.Check(t, err.Error(), expected)
+	}
+	if err := NewConfig().Decode(testConfig{}); err == nil {
+		t.Fatal("expect error, got nothing")
+	}
+}
+
+type testLevel int
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	for i, name := range []string{"debug", "info", "warn"} {
+		if string(text) == name {
+			*l = testLevel(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown level %q", text)
+}
+
+type testRate struct {
+	N   int           `cc:"count"`
+	Per time.Duration `cc:"per"`
+}
+
+func (r *testRate) UnmarshalCC(v Valuer) error {
+	if v.Config().Has("count") {
+		type plain testRate // without the UnmarshalCC method
+		return v.As((*plain)(r))
+	}
+	parts := strings.SplitN(v.String(), "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid rate %q, expected N/unit", v.Raw())
+	}
+	if _, err := fmt.Sscan(parts[0], &r.N); err != nil {
+		return fmt.Errorf("invalid rate %q: %v", v.Raw(), err)
+	}
+	d, err := time.ParseDuration("1" + parts[1])
+	if err != nil {
+		return err
+	}
+	r.Per = d
+	return nil
+}
+
+func TestConfigDecodeUnmarshaler(t *testing.T) {
+	c, err := NewConfigFromYAML([]byte(`
+level: warn
+levels: [debug, info]
+rate: 100/s
+rates:
+  api: {count: 5, per: 1m}
+started: 2006-01-02T15:04:05Z
+ip: 10.0.0.1
+`))
+	assert.Must(t, err)
+	var v struct {
+		Level   testLevel           `cc:"level"`
+		Levels  []testLevel         `cc:"levels"`
+		Rate    *testRate           `cc:"rate"`
+		Rates   map[string]testRate `cc:"rates"`
+		Started time.Time           `cc:"started"`
+		IP      net.IP              `cc:"ip"`
+	}
+	assert.Must(t, c.Decode(&v))
+	assert.Check(t, v.Level, testLevel(2))
+	assert.Check(t, fmt.Sprint(v.Levels), "[0 1]")
+	assert.Check(t, *v.Rate, testRate{N: 100, Per: time.Second})
+	assert.Check(t, v.Rates["api"], testRate{N: 5, Per: time.Minute})
+	assert.Check(t, v.Started.Equal(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)), true)
+	assert.Check(t, v.IP.String(), "10.0.0.1")
+
+	os.Setenv("CC_TEST_LEVEL", "info")
+	defer os.Unsetenv("CC_TEST_LEVEL")
+	c.BindEnv("level", "CC_TEST_LEVEL")
+	assert.Must(t, c.Decode(&v))
+	assert.Check(t, v.Level, testLevel(1))
+
+	cases := map[string]map[string]interface{}{
+		`level: unknown level "fatal"`:                   {"level": "fatal"},
+		"level: expected string, got int":                {"level": 1},
+		`levels[1]: unknown level "x"`:                   {"levels": []interface{}{"info", "x"}},
+		`rate: invalid rate "100", expected N/unit`:      {"rate": "100"},
+		"rates.api: count: expected integer, got string": {"rates": map[string]interface{}{"api": map[string]interface{}{"count": "x"}}},
+	}
+	for expected, kv := range cases {
+		err := NewConfigFrom(kv).Decode(&v)
+		if err == nil {
+			t.Fatalf("expect error %q, got nothing", expected)
+		}
+		assert.Check(t, err.Error(), expected)
+	}
+}
+
+func TestValueAs(t *testing.T) {
+	var level testLevel
+	assert.Must(t, NewValue("info").As(&level))
+	assert.Check(t, level, testLevel(1))
+	assert.Check(t, NewValue("x").As(&level).Error(), `unknown level "x"`)
+	assert.Check(t, NewValue(nil).As(&level), errNotExist)
+	assert.Check(t, NewValue("info").As(level) != nil, true)
+
+	var rate testRate
+	assert.Must(t, NewValue("10/m").As(&rate))
+	assert.Check(t, rate, testRate{N: 10, Per: time.Minute})
+
+	var n uint8
+	assert.Check(t, NewValue(256).As(&n).Error(), "256 overflows uint8")
+	var ports []int
+	assert.Must(t, NewValue([]interface{}{80, 443}).As(&ports))
+	assert.Check(t, fmt.Sprint(ports), "[80 443]")
+	assert.Check(t, NewValue([]interface{}{"x"}).As(&ports).Error(), "[0]: expected integer, got string")
+
+	var s struct {
+		Name string `cc:"name"`
+	}
+	assert.Must(t, NewValue(map[string]interface{}{"name": "cc"}).As(&s))
+	assert.Check(t, s.Name, "cc")
+}
+
+func TestConfigDecodeJSONNumberInterface(t *testing.T) {
+	c, err := NewConfigFromJSON([]byte(`{"any": 8080, "list": [1, 1.5, 18446744073709551615], "map": {"n": 1e3}}`))
+	assert.Must(t, err)
+	var v struct {
+		Any  interface{}            `cc:"any"`
+		List []interface{}          `cc:"list"`
+		Map  map[string]interface{} `cc:"map"`
+	}
+	assert.Must(t, c.Decode(&v))
+	assert.Check(t, v.Any, int64(8080))
+	assert.Check(t, v.List[0], int64(1))
+	assert.Check(t, v.List[1], 1.5)
+	assert.Check(t, v.List[2], 18446744073709551615.0)
+	assert.Check(t, v.Map["n"], 1000.0)
+}
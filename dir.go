@@ -0,0 +1,93 @@
+package cc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DirOption configures MergeFromDir.
+type DirOption func(o *dirOptions)
+
+type dirOptions struct {
+	filesAsKeys bool
+}
+
+// FilesAsKeys makes MergeFromDir take each file name as a top level key and
+// the raw file contents as the string value, e.g. the Kubernetes ConfigMap
+// volumes.
+func FilesAsKeys() DirOption {
+	return func(o *dirOptions) {
+		o.filesAsKeys = true
+	}
+}
+
+// backupSuffixes are the suffixes of the backup and temporary files.
+var backupSuffixes = []string{"~", ".bak", ".swp", ".tmp", ".orig", ".rej", ".rpmnew", ".rpmsave", ".dpkg-old", ".dpkg-new", ".dpkg-dist"}
+
+// MergeFromDir merges the config files in the directory in lexical order, e.g.
+// conf.d/10-base.yaml then conf.d/20-local.json, the files with unsupported
+// extensions, the hidden files, the backup files and the sub directories are
+// skipped. Each file is read as MergeFromFile does and the maps of the files
+// are merged deeply, e.g. conf.d/20-local.yaml with `db: {host: x}` keeps
+// db.port of conf.d/10-base.yaml. The symbolic links are followed.
+func (c *Config) MergeFromDir(dir string, opts ...DirOption) error {
+	o := &dirOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	infos, err := ioutil.ReadDir(dir) // sorted by name
+	if err != nil {
+		return err
+	}
+	merged := map[string]interface{}{}
+	for _, info := range infos {
+		name := info.Name()
+		if skipFile(name) {
+			continue
+		}
+		fpath := filepath.Join(dir, name)
+		if info.Mode()&os.ModeSymlink != 0 {
+			if info, err = os.Stat(fpath); err != nil {
+				return err
+			}
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		if o.filesAsKeys {
+			content, err := ioutil.ReadFile(fpath)
+			if err != nil {
+				return err
+			}
+			c.kv[name] = string(content)
+			continue
+		}
+		if _, ok := unmarshalers[filepath.Ext(name)]; !ok {
+			continue
+		}
+		data, err := c.includeFile(osFileSystem, fpath, nil)
+		if err != nil {
+			return err
+		}
+		deepMerge(merged, data, "", "", nil)
+	}
+	c.mergeKV(merged)
+	return nil
+}
+
+// skipFile reports whether the file is hidden or a backup file.
+func skipFile(name string) bool {
+	if strings.HasPrefix(name, ".") || (strings.HasPrefix(name, "#") && strings.HasSuffix(name, "#")) {
+		return true
+	}
+	for _, suffix := range backupSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
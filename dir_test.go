@@ -0,0 +1,57 @@
+package cc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestMergeFromDir(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"10-base.yaml":       "name: base\nport: 80\nlevel: info\ndb: {host: x, port: 5432}\n",
+		"20-local.json":      `{"port": 8080, "db": {"host": "y"}}`,
+		"30-extra.yml":       "level: debug\n",
+		"30-extra.yml~":      "level: backup\n",
+		"30-extra.yml.bak":   "level: backup\n",
+		".40-hidden.yaml":    "level: hidden\n",
+		"#50-emacs.yaml#":    "level: emacs\n",
+		"README.md":          "# ignored",
+		"sub/60-nested.yaml": "level: nested\n",
+	})
+	defer os.RemoveAll(dir)
+
+	c := NewConfig()
+	assert.Must(t, c.MergeFromDir(dir))
+	assert.Check(t, c.String("name"), "base")
+	assert.Check(t, c.Int("port"), 8080)
+	assert.Check(t, c.String("level"), "debug")
+	assert.Check(t, c.Config("db").String("host"), "y")
+	assert.Check(t, c.Config("db").Int("port"), 5432)
+	assert.Check(t, len(c.KV()), 4)
+
+	err := c.MergeFromDir(filepath.Join(dir, "nope"))
+	if !os.IsNotExist(err) {
+		t.Fatalf("expect not exist error, got %v", err)
+	}
+}
+
+func TestMergeFromDirFilesAsKeys(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"..2024_01_01/host": "localhost",
+		"..2024_01_01/port": "8080\n",
+		"ignored.bak":       "x",
+	})
+	defer os.RemoveAll(dir)
+	// The layout of the Kubernetes ConfigMap volumes.
+	assert.Must(t, os.Symlink("..2024_01_01", filepath.Join(dir, "..data")))
+	assert.Must(t, os.Symlink("..data/host", filepath.Join(dir, "host")))
+	assert.Must(t, os.Symlink("..data/port", filepath.Join(dir, "port")))
+
+	c := NewConfig()
+	assert.Must(t, c.MergeFromDir(dir, FilesAsKeys()))
+	assert.Check(t, c.String("host"), "localhost")
+	assert.Check(t, c.String("port"), "8080\n")
+	assert.Check(t, len(c.KV()), 2)
+}
@@ -1,5 +1,6 @@
 // Package cc is a very flexible configuration management library for humans,
-// which is easy to use and support YAML and JSON only.
+// which is easy to use and supports YAML, JSON(JSONC/JSON5), dotenv, INI, Java
+// properties and HCL.
 //
 //
 // Usage
@@ -19,6 +20,8 @@
 //		// environment variables
 //		os.Setenv("float_env", "11.11")
 //		c.Float("float_env")
+//		c.SetEnvPrefix("myapp")  // or c.BindEnv("port", "PORT")
+//		c.Int("port")  // MYAPP_PORT
 //
 //		// flags
 //		flag.Int("flag", 33, "usage")
@@ -28,6 +31,86 @@
 // NOTE: we take empty string, false boolean and zero number value as default
 // value in flags, and those value has no priority.
 //
+// The Uint, Uint64, Int32 and Float32 families convert the numbers exactly,
+// the E-suffixed getters return an error if the number overflows, e.g.
+// "port: -1 overflows uint", the others return the default value.
+//
+// The getters only convert the numbers between the numeric types by default,
+// the lenient coercion also parses the strings into numbers, bools and
+// durations, and formats the numbers and bools into strings:
+//
+//		c.SetCoercion(cc.CoerceLenient)
+//		c.Int("port")  // 8080 from "8080"
+//
+// The slice getters convert every element, e.g. IntSlice, the And variants
+// validate every element, the environment variables and flags are split by
+// commas or spaces, StringSliceFlag collects the repeated flags:
+//
+//		flag.Var(&cc.StringSliceFlag{}, "tag", "usage")
+//		c.IntSliceAnd("ports", "N>0&&N<65536")
+//
+// The Time(RFC3339 by default), Location, URL, IP, IPNet, Prefix, HostPort,
+// Regexp and FileMode(octal digits) getters parse the strings, the
+// E-suffixed ones return the descriptive errors:
+//
+//		c.Time("since", time.RFC3339, "2006-01-02")
+//		host, port, err := c.HostPortE("listen")
+//
+//
+// Composing Config Files
+//
+// MergeFromFile honors the "include"(or "$import") directive, the value is a
+// path or a list of paths, the paths can be globs and the relative paths are
+// relative to the including file:
+//
+//		# prod.yaml
+//		include:
+//		    - base.yaml
+//		    - secrets/*.yaml
+//		name: prod
+//
+// The included files are merged before the including file by default, the
+// maps are merged deeply, see SetIncludeOrder.
+//
+// The profiles overlay the base config file, the maps are merged deeply:
+//
+//		// loads config.yaml, config.prod.yaml and config.eu.yaml, and the
+//		// sections like `profiles: {prod: {...}}` in them.
+//		c, err := cc.Load(cc.File("config.yaml"), cc.Profiles("prod", "eu"))
+//		c.Source("db.host")  // "config.yaml#profiles.prod"
+//
+// Without Profiles, the active profiles are from the "profile" flag or the
+// APP_PROFILE environment variable, see ProfileFlag and ProfileEnv.
+//
+// The config files in a directory can be merged deeply in lexical order, or
+// each file name is a key and the raw contents is the value with FilesAsKeys:
+//
+//		err := c.MergeFromDir("/etc/myapp/conf.d")  // 10-base.yaml, 20-local.json, ...
+//
+// The config can be loaded from io.Reader, fs.FS and stdin too, see
+// MergeFromReader, MergeFromFS and MergeFromFile.
+//
+// The dotenv files can be merged into the config data, or be the environment
+// variables layer, see MergeFromDotenv and DotenvAsEnv.
+//
+// The sections of INI and the dotted keys of INI and Java properties become
+// the nested configs, the values in them are always strings, e.g. "1.10" and
+// "007" are kept as they are, the lenient coercion converts them into the
+// numbers and bools in the getters. The value of a key which is also the
+// prefix of the other keys is kept under "_value", see SetCoercion,
+// MergeFromINI and MergeFromProperties.
+//
+// The blocks of HCL become the nested configs, the labels are the nested keys
+// and the repeated blocks become a list, only the literal expressions are
+// supported, see MergeFromHCL.
+//
+// The JSONC and JSON5 files allow the comments, trailing commas, unquoted keys,
+// single-quoted strings and hex numbers, see MergeFromJSON5 and SetLenientJSON.
+// The JSON numbers are kept as json.Number, so the large integers are
+// converted exactly, the getters return the default value if it overflows,
+// and the numbers overflowing float64, e.g. 1e400, are rejected on merging.
+// Decode converts them into int64 or float64 for the interface{} fields.
+//
 //
 // Default Configs
 //
@@ -52,6 +135,13 @@
 //
 //		s, ok := c.StringAnd("name", "^c")
 //
+// The prefixed patterns can be used instead of crafting regexps:
+//
+//		s, ok := c.StringAnd("level", "enum:debug|info|warn")
+//		s, ok := c.StringAnd("host", "glob:*.example.com")
+//		s, ok := c.StringAnd("password", "len:N>=8&&N<=64")
+//		s, ok := c.StringAnd("email", "format:email")  // or hostname, uri, ipv4, cidr, semver
+//
 // Or, the make the string value as a pattern:
 //
 //		p := c.Pattern("pattern_key_name")
@@ -72,5 +162,102 @@
 //      nf = c.FloatAndOr("int_key", "N/100>=0.3", 40)
 //      d := c.DurationAndOr("duration", "N>20&&N<=100", 50)
 //
+// Ranges, sets and ternary conditions are supported as well:
+//
+//		ni, ok := c.IntAnd("int_key", "N in [30, 80]")  // or "N in (30, 80]"
+//		ni, ok := c.IntAnd("int_key", "N not in {1, 2, 5}")
+//		nf, ok := c.FloatAnd("float_key", "N>100 ? N%10==0 : N>0")
+//
+// Patterns can be composed by All, Any and Not, or by a list of patterns in config
+// file, which means all of them must be valid:
+//
+//		var p cc.Patterner = cc.All(cc.NewPattern("N>=30"), cc.Not(cc.NewPattern("N in {50, 60}")))
+//
+//		// threhold: ["N>=30", "N<=80", {not: "N in {50, 60}"}]
+//		p = c.Pattern("threhold")
+//
+// A Pattern is immutable and safe for concurrent use, compile it eagerly and
+// find out why a value is not valid:
+//
+//		p, err := cc.CompilePattern("N>=50")
+//		err = p.ValidateIntE(40)  // 40 does not satisfy N >= 50
+//
+// The Patterners created by cc, e.g. by All and Config.Pattern, implement
+// PatternerE which has the E-suffixed methods.
+//
+// The compiled patterns are cached in a bounded LRU cache which is shared by
+// all the And/AndOr families, see SetPatternCacheSize and GetPatternCacheStats.
+//
 // NOTE: bit operation is not supported.
+//
+//
+// JSON Schema
+//
+// The whole config can be validated against a JSON Schema, all the violations
+// are returned with JSON pointer paths:
+//
+//		if err := c.ValidateSchema(schema); err != nil {
+//			for _, e := range err.(cc.SchemaErrors) {
+//				fmt.Println(e.Path, e.Message)
+//			}
+//		}
+//
+// Or, declare the keys in Go, which can also register the flags, bind the
+// environment variables and generate the sample config and Markdown reference:
+//
+//		s := cc.NewSchema()
+//		s.Int("port", cc.Default(8080), cc.Validate("N>0&&N<65536"), cc.Doc("listen port"),
+//			cc.Env("PORT"), cc.Flag("port"))
+//		s.RegisterFlags(flag.CommandLine)
+//		s.Apply(c)
+//		err := s.Check(c)
+//
+//
+// Interpolation
+//
+// The references in string values are resolved lazily by the getters, so the
+// values are always up to date after merging:
+//
+//		${other.key}           the value of another key, dotted path from the root
+//		                       Config, the numbers are list indexes, e.g. ${list.0}
+//		${env:HOME}            the environment variable
+//		${env:PORT:-8080}      the environment variable, or the default if it is
+//		                       unset or empty
+//		${file:/run/secrets/x} the file contents without the trailing newlines
+//		$${...}                the literal "${...}"
+//
+// If the whole value is a single reference, the value keeps the type of the
+// referenced value, e.g. "${ports.http}" is an int, the environment variables
+// and files are strings, but the getters and Decode convert them leniently as
+// the bound environment variables, e.g. c.Int on "${env:PORT:-8080}". Use
+// Resolve to check the references.
+//
+// The secret references "secret://file/run/secrets/x" and "secret://env/X"
+// are resolved into Secrets, which are redacted in the logs and dumps, the
+// strings embedding them are Secrets too, see SecretProvider for the other
+// sources:
+//
+//		password := c.Secret("password").Reveal()  // or c.String("password")
+//
+//
+// Struct Decoding
+//
+// The Config can be decoded into a struct, the keys are given by the `cc` tags
+// and the values can be validated by the `pattern` tags:
+//
+//		type Server struct {
+//			Port    int           `cc:"port" pattern:"N>0&&N<65536"`
+//			Timeout time.Duration `cc:"timeout"`
+//		}
+//		var s Server
+//		err := c.Decode(&s)
+//
+// The types implementing Unmarshaler or encoding.TextUnmarshaler decode
+// themselves, Valuer.As decodes a single value the same way:
+//
+//		var level Level
+//		err := c.Value("level").As(&level)
+//
+// The command cmd/ccgen generates the structs and the typed loader from a
+// sample config file.
 package cc
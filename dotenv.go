@@ -0,0 +1,307 @@
+package cc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// DotenvOption configures MergeFromDotenv.
+type DotenvOption func(o *dotenvOptions)
+
+type dotenvOptions struct {
+	asEnv bool
+}
+
+// DotenvAsEnv makes MergeFromDotenv inject the variables as the environment
+// variables layer which the getters consult, instead of merging them into the
+// config data. The real environment variables have priority.
+func DotenvAsEnv() DotenvOption {
+	return func(o *dotenvOptions) {
+		o.asEnv = true
+	}
+}
+
+// MergeFromDotenv merges the variables from the dotenv file, the names are
+// lower-cased and "__" separates the nested keys, e.g. DB__HOST=x becomes
+// {"db": {"host": "x"}}, the values are strings. The files with the ".env"
+// extension are merged the same way by MergeFromFile.
+//
+// The syntax is the same as docker-compose:
+//
+//	# comment
+//	export NAME=value          # the export prefix is optional
+//	UNQUOTED=a b c             # inline comment
+//	DOUBLE="line1\nline2 $NAME ${NAME:-default}"
+//	SINGLE='no escapes or $expansions'
+//	MULTI="first line
+//	second line"
+//
+// The variables are expanded from the former variables in the file or the
+// environment variables.
+func (c *Config) MergeFromDotenv(fpath string, opts ...DotenvOption) error {
+	o := &dotenvOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	content, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return err
+	}
+	vars, names, err := parseDotenv(content)
+	if err != nil {
+		return fmt.Errorf("%s: %v", fpath, err)
+	}
+	if !o.asEnv {
+		c.mergeKV(dotenvToKV(vars, names))
+		return nil
+	}
+	root := c.rootConfig()
+	if root.dotenv == nil {
+		root.dotenv = map[string]string{}
+	}
+	for name, v := range vars {
+		root.dotenv[name] = v
+	}
+	return nil
+}
+
+func unmarshalDotenv(b []byte) (map[string]interface{}, error) {
+	vars, names, err := parseDotenv(b)
+	if err != nil {
+		return nil, err
+	}
+	return dotenvToKV(vars, names), nil
+}
+
+// dotenvToKV converts the variables into the nested keys in order.
+func dotenvToKV(vars map[string]string, names []string) map[string]interface{} {
+	kv := map[string]interface{}{}
+	for _, name := range names {
+		keys := strings.Split(strings.ToLower(name), "__")
+		m := kv
+		for _, k := range keys[:len(keys)-1] {
+			child, ok := m[k].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				m[k] = child
+			}
+			m = child
+		}
+		m[keys[len(keys)-1]] = vars[name]
+	}
+	return kv
+}
+
+// parseDotenv parses the dotenv file, returns the variables and the names in order.
+func parseDotenv(b []byte) (map[string]string, []string, error) {
+	p := &dotenvParser{s: strings.Replace(string(b), "\r\n", "\n", -1), line: 1, vars: map[string]string{}}
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.s) {
+			return p.vars, p.names, nil
+		}
+		line := p.line
+		if err := p.parseLine(); err != nil {
+			return nil, nil, fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+}
+
+type dotenvParser struct {
+	s     string
+	pos   int
+	line  int
+	vars  map[string]string
+	names []string
+}
+
+// skipBlank skips the blank lines and the comment lines.
+func (p *dotenvParser) skipBlank() {
+	for p.pos < len(p.s) {
+		switch c := p.s[p.pos]; {
+		case c == '\n':
+			p.line++
+			p.pos++
+		case c == ' ' || c == '\t':
+			p.pos++
+		case c == '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for p.pos < len(p.s) && p.s[p.pos] != '\n' {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func isDotenvNameChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+		(!first && ((c >= '0' && c <= '9') || c == '.' || c == '-'))
+}
+
+func (p *dotenvParser) parseName() string {
+	start := p.pos
+	for p.pos < len(p.s) && isDotenvNameChar(p.s[p.pos], p.pos == start) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *dotenvParser) parseLine() error {
+	name := p.parseName()
+	if name == "export" && p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.skipSpaces()
+		name = p.parseName()
+	}
+	if name == "" {
+		return fmt.Errorf("invalid variable name")
+	}
+	p.skipSpaces()
+	if p.pos >= len(p.s) || p.s[p.pos] != '=' {
+		return fmt.Errorf("expected '=' after %s", name)
+	}
+	p.pos++
+	p.skipSpaces()
+
+	var value string
+	var err error
+	if p.pos < len(p.s) && (p.s[p.pos] == '"' || p.s[p.pos] == '\'') {
+		value, err = p.parseQuoted(p.s[p.pos])
+		if err != nil {
+			return err
+		}
+		p.skipSpaces()
+		if p.pos < len(p.s) && p.s[p.pos] != '\n' && p.s[p.pos] != '#' {
+			return fmt.Errorf("unexpected characters after the quoted value of %s", name)
+		}
+		p.skipLine()
+	} else {
+		value = p.parseUnquoted()
+	}
+
+	if _, in := p.vars[name]; !in {
+		p.names = append(p.names, name)
+	}
+	p.vars[name] = value
+	return nil
+}
+
+// parseUnquoted parses the value until the end of line or the inline comment.
+func (p *dotenvParser) parseUnquoted() string {
+	start := p.pos
+	p.skipLine()
+	raw := p.s[start:p.pos]
+	if idx := strings.Index(raw, " #"); idx >= 0 {
+		raw = raw[:idx]
+	}
+	if idx := strings.Index(raw, "\t#"); idx >= 0 {
+		raw = raw[:idx]
+	}
+	return p.expand(strings.TrimSpace(raw))
+}
+
+// parseQuoted parses the single or double quoted value which can span
+// multiple lines, the escapes and expansions are only for double quotes.
+func (p *dotenvParser) parseQuoted(quote byte) (string, error) {
+	p.pos++ // the open quote
+	var buf []byte
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		p.pos++
+		switch {
+		case c == quote:
+			if quote == '\'' {
+				return string(buf), nil
+			}
+			return p.expand(string(buf)), nil
+		case c == '\n':
+			p.line++
+			buf = append(buf, c)
+		case c == '\\' && quote == '"' && p.pos < len(p.s):
+			e := p.s[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				buf = append(buf, '\n')
+			case 't':
+				buf = append(buf, '\t')
+			case 'r':
+				buf = append(buf, '\r')
+			case '$':
+				// Keep the escaped "$" from the expansion.
+				buf = append(buf, '\\', '$')
+			case '"', '\\':
+				buf = append(buf, e)
+			default:
+				buf = append(buf, '\\', e)
+			}
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return "", fmt.Errorf("unclosed quote")
+}
+
+// expand expands $NAME, ${NAME} and ${NAME:-default}, "\$" is a literal "$".
+func (p *dotenvParser) expand(s string) string {
+	if !strings.Contains(s, "$") {
+		return s
+	}
+	var buf []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '$':
+			buf = append(buf, '$')
+			i++
+		case c == '$' && i+1 < len(s) && s[i+1] == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				buf = append(buf, s[i:]...)
+				i = len(s)
+				continue
+			}
+			ref := s[i+2 : i+end]
+			name, deflt := ref, ""
+			idx := strings.Index(ref, ":-")
+			if idx >= 0 {
+				name, deflt = ref[:idx], ref[idx+2:]
+			}
+			v := p.lookup(name)
+			if v == "" && idx >= 0 {
+				v = deflt
+			}
+			buf = append(buf, v...)
+			i += end
+		case c == '$' && i+1 < len(s) && isDotenvNameChar(s[i+1], true):
+			j := i + 1
+			for j < len(s) && isDotenvNameChar(s[j], j == i+1) && s[j] != '.' && s[j] != '-' {
+				j++
+			}
+			buf = append(buf, p.lookup(s[i+1:j])...)
+			i = j - 1
+		default:
+			buf = append(buf, c)
+		}
+	}
+	return string(buf)
+}
+
+func (p *dotenvParser) lookup(name string) string {
+	if v, ok := p.vars[name]; ok {
+		return v
+	}
+	return os.Getenv(name)
+}
@@ -0,0 +1,98 @@
+package cc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestParseDotenv(t *testing.T) {
+	os.Setenv("CC_TEST_USER", "cc")
+	defer os.Unsetenv("CC_TEST_USER")
+
+	vars, names, err := parseDotenv([]byte(`
+# comment
+export NAME=app
+  SPACED = a b c   # inline comment
+HASH=a#b
+EMPTY=
+DOUBLE="line1\nline2\t\"quoted\" \\ \$NAME $NAME"
+SINGLE='no \n or $NAME' # comment
+MULTI="first
+second"
+EXPAND=${NAME}-${CC_TEST_USER}-${NOPE:-default}-$NAME.x
+NAME=override
+` + "CRLF=x\r\n"))
+	assert.Must(t, err)
+	assert.Check(t, len(names), 9)
+	assert.Check(t, names[0], "NAME")
+	for name, expected := range map[string]string{
+		"NAME":   "override",
+		"SPACED": "a b c",
+		"HASH":   "a#b",
+		"EMPTY":  "",
+		"DOUBLE": "line1\nline2\t\"quoted\" \\ $NAME app",
+		"SINGLE": `no \n or $NAME`,
+		"MULTI":  "first\nsecond",
+		"EXPAND": "app-cc-default-app.x",
+		"CRLF":   "x",
+	} {
+		assert.Check(t, vars[name], expected)
+	}
+
+	for data, expected := range map[string]string{
+		"A=1\nB":           "line 2: expected '=' after B",
+		"A=1\n=2":          "line 2: invalid variable name",
+		"A=\"x\ny":         "line 1: unclosed quote",
+		"A=1\nB='x' y\n":   "line 2: unexpected characters after the quoted value of B",
+		"export A=1\n1A=2": "line 2: invalid variable name",
+	} {
+		_, _, err := parseDotenv([]byte(data))
+		if err == nil {
+			t.Fatalf("expect error for %q", data)
+		}
+		assert.Check(t, err.Error(), expected)
+	}
+}
+
+func TestMergeFromDotenv(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		".env":     "PORT=8080\nDB__HOST=localhost\nDB__PORT=5432\nLOG_LEVEL=debug\n",
+		"prod.env": "DB__HOST=db.prod\n",
+	})
+	defer os.RemoveAll(dir)
+
+	c := NewConfig()
+	assert.Must(t, c.MergeFromFile(filepath.Join(dir, ".env")))
+	assert.Check(t, c.String("port"), "8080")
+	assert.Check(t, c.String("log_level"), "debug")
+	assert.Check(t, c.Config("db").String("host"), "localhost")
+	assert.Must(t, c.MergeFromDotenv(filepath.Join(dir, "prod.env")))
+	assert.Check(t, c.Config("db").String("host"), "db.prod")
+
+	c = NewConfig()
+	assert.Must(t, c.MergeFromDotenv(filepath.Join(dir, ".env"), DotenvAsEnv()))
+	assert.Check(t, c.Has("LOG_LEVEL"), true)
+	assert.Check(t, c.Int("PORT"), 8080)
+	assert.Check(t, c.StringOr("LOG_LEVEL", "info"), "debug")
+	c.Set("level", "${env:LOG_LEVEL}")
+	assert.Check(t, c.String("level"), "debug")
+	c.BindEnv("log_level", "LOG_LEVEL")
+	assert.Check(t, c.String("log_level"), "debug")
+
+	// The real environment variables have priority.
+	os.Setenv("LOG_LEVEL", "warn")
+	defer os.Unsetenv("LOG_LEVEL")
+	assert.Check(t, c.String("log_level"), "warn")
+	assert.Check(t, len(c.KV()), 1)
+
+	// The child Configs see the variables too.
+	c.Set("db", map[string]interface{}{"name": "app"})
+	db := c.Config("db")
+	assert.Check(t, db.String("DB__HOST"), "localhost")
+	db.(*Config).BindEnv("host", "DB__HOST")
+	assert.Check(t, db.String("host"), "localhost")
+	assert.Check(t, c.Config("db").String("name"), "app")
+}
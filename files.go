@@ -0,0 +1,125 @@
+package cc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// fileSystem is where the config files and the included files are read from.
+type fileSystem struct {
+	readFile func(name string) ([]byte, error)
+	glob     func(pattern string) ([]string, error)
+	join     func(elem ...string) string
+	dir      func(name string) string
+	isAbs    func(name string) bool
+	abs      func(name string) (string, error)
+}
+
+var osFileSystem = &fileSystem{
+	readFile: ioutil.ReadFile,
+	glob:     filepath.Glob,
+	join:     filepath.Join,
+	dir:      filepath.Dir,
+	isAbs:    filepath.IsAbs,
+	abs:      filepath.Abs,
+}
+
+func newFileSystem(fsys fs.FS) *fileSystem {
+	return &fileSystem{
+		readFile: func(name string) ([]byte, error) { return fs.ReadFile(fsys, name) },
+		glob:     func(pattern string) ([]string, error) { return fs.Glob(fsys, pattern) },
+		join:     path.Join,
+		dir:      path.Dir,
+		isAbs:    func(string) bool { return false },
+		abs:      func(name string) (string, error) { return path.Clean(name), nil },
+	}
+}
+
+// MergeFromFS merges config data from the file in fsys, e.g. embed.FS and
+// fstest.MapFS, the format is detected by the extension or the contents,
+// see MergeFromReader. The include directives are honored in fsys.
+func (c *Config) MergeFromFS(fsys fs.FS, fpath string) error {
+	return c.mergeFromFile(newFileSystem(fsys), fpath, nil)
+}
+
+// MergeFromReader merges config data from the reader, the format is the file
+// extension without the dot, e.g. "yaml" and "json", or empty to detect it by
+// the contents, the data starts with '{' is JSON, otherwise YAML. The included
+// files are relative to the working directory.
+func (c *Config) MergeFromReader(r io.Reader, format string) error {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	ext := ""
+	if format != "" {
+		ext = "." + strings.TrimPrefix(format, ".")
+	}
+	unmarshal, ok := c.unmarshaler(ext)
+	if !ok {
+		return fmt.Errorf("unsupported config format: %s", format)
+	}
+	data, err := unmarshal(content)
+	if err != nil {
+		return err
+	}
+	return c.mergeWithIncludes(osFileSystem, data, "(reader)", ".", nil)
+}
+
+// unmarshalFile reads the file, "-" means the stdin, the format is detected by
+// the extension, or the contents if no extension.
+func (c *Config) unmarshalFile(fsys *fileSystem, fpath string) (map[string]interface{}, error) {
+	ext := filepath.Ext(fpath)
+	if fpath == "-" {
+		ext = ""
+	}
+	unmarshal, ok := c.unmarshaler(ext)
+	if !ok {
+		return nil, fmt.Errorf("unsupported config file type: %s", fpath)
+	}
+
+	var content []byte
+	var err error
+	if fpath == "-" && fsys == osFileSystem {
+		content, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		content, err = fsys.readFile(fpath)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return unmarshal(content)
+}
+
+// unmarshaler returns the unmarshal function of the extension, the empty
+// extension means detecting the format by the contents.
+func (c *Config) unmarshaler(ext string) (func(b []byte) (map[string]interface{}, error), bool) {
+	switch {
+	case ext == "":
+		return c.unmarshalSniffed, true
+	case ext == ".json" && c.lenientJSON:
+		return unmarshalJSON5, true
+	}
+	unmarshal, ok := unmarshalers[ext]
+	return unmarshal, ok
+}
+
+// unmarshalSniffed unmarshals the data as JSON if it starts with '{',
+// otherwise YAML.
+func (c *Config) unmarshalSniffed(b []byte) (map[string]interface{}, error) {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(b, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if !bytes.HasPrefix(trimmed, []byte("{")) {
+		return unmarshalYAML(b)
+	}
+	if c.lenientJSON {
+		return unmarshalJSON5(trimmed)
+	}
+	return unmarshalJSON(trimmed)
+}
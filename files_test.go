@@ -0,0 +1,75 @@
+package cc
+
+import (
+	"embed"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/damnever/cc/assert"
+)
+
+//go:embed example/example.yaml example/example.json
+var exampleFS embed.FS
+
+func TestMergeFromFS(t *testing.T) {
+	c := NewConfig()
+	assert.Must(t, c.MergeFromFS(exampleFS, "example/example.yaml"))
+	assert.Check(t, c.String("name"), "cc")
+	assert.Check(t, c.Config("map").Config("child").Int("key_three"), 33)
+
+	fsys := fstest.MapFS{
+		"conf/app.yaml":       {Data: []byte("include: [base.json, secrets/*]\nname: app\n")},
+		"conf/base.json":      {Data: []byte(`{"name": "base", "port": 80}`)},
+		"conf/secrets/token":  {Data: []byte("token: t\n")},
+		"conf/secrets/nested": {Data: []byte(`{"nested": {"k": "v"}}`)},
+		"conf/loop.yaml":      {Data: []byte("include: ../conf/loop.yaml\n")},
+		"conf/bad.toml":       {Data: []byte("a = 1")},
+	}
+	c = NewConfig()
+	assert.Must(t, c.MergeFromFS(fsys, "conf/app.yaml"))
+	assert.Check(t, c.String("name"), "app")
+	assert.Check(t, c.Int("port"), 80)
+	assert.Check(t, c.String("token"), "t")
+	assert.Check(t, c.Config("nested").String("k"), "v")
+
+	err := c.MergeFromFS(fsys, "conf/loop.yaml")
+	assert.Check(t, err.Error(), "include cycle: conf/loop.yaml -> conf/loop.yaml")
+	err = c.MergeFromFS(fsys, "conf/bad.toml")
+	assert.Check(t, err.Error(), "unsupported config file type: conf/bad.toml")
+}
+
+func TestMergeFromReader(t *testing.T) {
+	c := NewConfig()
+	assert.Must(t, c.MergeFromReader(strings.NewReader("a: 1\n"), "yaml"))
+	assert.Must(t, c.MergeFromReader(strings.NewReader(`{"b": 2}`), ".json"))
+	assert.Must(t, c.MergeFromReader(strings.NewReader("\xef\xbb\xbf\n  {\"c\": 3}"), ""))
+	assert.Must(t, c.MergeFromReader(strings.NewReader("d: 4"), ""))
+	assert.Check(t, c.Int("a"), 1)
+	assert.Check(t, c.Float("b"), 2.0)
+	assert.Check(t, c.Float("c"), 3.0)
+	assert.Check(t, c.Int("d"), 4)
+
+	err := c.MergeFromReader(strings.NewReader("a = 1"), "toml")
+	assert.Check(t, err.Error(), "unsupported config format: toml")
+}
+
+func TestMergeFromStdin(t *testing.T) {
+	f, err := ioutil.TempFile("", "cc")
+	assert.Must(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(`{"name": "stdin"}`)
+	assert.Must(t, err)
+	_, err = f.Seek(0, 0)
+	assert.Must(t, err)
+
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+
+	c := NewConfig()
+	assert.Must(t, c.MergeFromFile("-"))
+	assert.Check(t, c.String("name"), "stdin")
+}
@@ -0,0 +1,59 @@
+package cc
+
+import (
+	"testing"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestFormats(t *testing.T) {
+	cases := map[string]map[string]bool{
+		"email": {
+			"cc@example.com":      true,
+			"cc <cc@example.com>": false,
+			"example.com":         false,
+		},
+		"hostname": {
+			"example.com":    true,
+			"localhost":      true,
+			"a-b.example":    true,
+			"-a.example.com": false,
+			"a..com":         false,
+			"a_b.com":        false,
+		},
+		"uri": {
+			"https://example.com/x?y=z": true,
+			"mailto:cc@example.com":     true,
+			"/relative/path":            false,
+			"example.com":               false,
+		},
+		"ipv4": {
+			"127.0.0.1":        true,
+			"::1":              false,
+			"::ffff:127.0.0.1": false,
+			"256.0.0.1":        false,
+		},
+		"cidr": {
+			"10.0.0.0/8":  true,
+			"fe80::/10":   true,
+			"10.0.0.0":    false,
+			"10.0.0.0/33": false,
+		},
+		"semver": {
+			"1.2.3":            true,
+			"1.0.0-rc.1+b.123": true,
+			"1.2":              false,
+			"01.2.3":           false,
+			"v1.2.3":           false,
+		},
+	}
+	for name, values := range cases {
+		p := NewPattern("format:" + name)
+		assert.Must(t, p.Err())
+		for s, expect := range values {
+			if p.ValidateString(s) != expect {
+				t.Fatalf("format:%s: expect %v for %q", name, expect, s)
+			}
+		}
+	}
+}
@@ -0,0 +1,451 @@
+package cc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MergeFromHCL merges data from HCL bytes, the value from same name will be replaced.
+//
+//	name = "app"
+//	ports = [80, 443]
+//	database {
+//	  host = "localhost"
+//	}
+//	service "web" {
+//	  motd = <<-EOT
+//	    hello
+//	  EOT
+//	}
+//	upstream { addr = "a" }
+//	upstream { addr = "b" }
+//
+// The blocks become the nested maps, the labels are the nested keys, e.g.
+// c.Config("service").Config("web").String("motd"), the repeated blocks become
+// the lists of maps, e.g. c.Value("upstream").List(). The expressions are
+// limited to the literals: strings, heredocs, numbers, bools, null, lists and
+// objects, the "${...}" in strings are kept as they are, see Interpolation.
+func (c *Config) MergeFromHCL(b []byte) error {
+	data, err := unmarshalHCL(b)
+	if err != nil {
+		return err
+	}
+	c.mergeKV(data)
+	return nil
+}
+
+func unmarshalHCL(b []byte) (map[string]interface{}, error) {
+	p := &hclParser{s: string(b)}
+	body, err := p.parseBody(false)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %v", 1+strings.Count(p.s[:p.pos], "\n"), err)
+	}
+	return body, nil
+}
+
+type hclParser struct {
+	s   string
+	pos int
+}
+
+func (p *hclParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *hclParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+// skipSpaces skips the spaces and comments, and the newlines if newlines is true.
+func (p *hclParser) skipSpaces(newlines bool) {
+	for !p.eof() {
+		c := p.s[p.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.pos++
+		case c == '#' || strings.HasPrefix(p.s[p.pos:], "//"):
+			for !p.eof() && p.s[p.pos] != '\n' {
+				p.pos++
+			}
+		case strings.HasPrefix(p.s[p.pos:], "/*"):
+			end := strings.Index(p.s[p.pos+2:], "*/")
+			if end < 0 {
+				p.pos = len(p.s)
+				return
+			}
+			p.pos += end + 4
+		default:
+			return
+		}
+	}
+}
+
+func isHCLIdentChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+		(!first && ((c >= '0' && c <= '9') || c == '-'))
+}
+
+func (p *hclParser) parseIdent() string {
+	start := p.pos
+	for !p.eof() && isHCLIdentChar(p.s[p.pos], p.pos == start) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// parseKey parses an identifier or a quoted string.
+func (p *hclParser) parseKey() (string, error) {
+	if p.peek() == '"' {
+		return p.parseString()
+	}
+	if key := p.parseIdent(); key != "" {
+		return key, nil
+	}
+	return "", fmt.Errorf("expected identifier, got %q", p.peek())
+}
+
+// parseBody parses the attributes and blocks until EOF, or '}' if closing.
+func (p *hclParser) parseBody(closing bool) (map[string]interface{}, error) {
+	body := map[string]interface{}{}
+	attrs := map[string]bool{}
+	for {
+		p.skipSpaces(true)
+		switch {
+		case p.eof():
+			if closing {
+				return nil, fmt.Errorf("unclosed block")
+			}
+			return body, nil
+		case p.peek() == '}':
+			if !closing {
+				return nil, fmt.Errorf("unexpected '}'")
+			}
+			p.pos++
+			return body, nil
+		}
+
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces(false)
+		if c := p.peek(); c == '=' || c == ':' {
+			p.pos++
+			if _, in := body[key]; in {
+				return nil, fmt.Errorf("duplicate key %q", key)
+			}
+			p.skipSpaces(false)
+			v, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			body[key] = v
+			attrs[key] = true
+			continue
+		}
+
+		path := []string{key}
+		for p.peek() != '{' {
+			label, err := p.parseKey()
+			if err != nil {
+				return nil, fmt.Errorf("expected '=', label or '{' after %q", key)
+			}
+			path = append(path, label)
+			p.skipSpaces(false)
+		}
+		p.pos++
+		if attrs[key] {
+			return nil, fmt.Errorf("duplicate key %q", key)
+		}
+		content, err := p.parseBody(true)
+		if err != nil {
+			return nil, err
+		}
+		if err := addHCLBlock(body, path, content); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// addHCLBlock adds the block by the type and labels, the repeated blocks
+// become a list.
+func addHCLBlock(body map[string]interface{}, path []string, content map[string]interface{}) error {
+	m := body
+	for _, k := range path[:len(path)-1] {
+		switch child := m[k].(type) {
+		case nil:
+			next := map[string]interface{}{}
+			m[k] = next
+			m = next
+		case map[string]interface{}:
+			m = child
+		default:
+			return fmt.Errorf("conflicting block %q", strings.Join(path, " "))
+		}
+	}
+	last := path[len(path)-1]
+	switch existing := m[last].(type) {
+	case nil:
+		m[last] = content
+	case map[string]interface{}:
+		m[last] = []interface{}{existing, content}
+	case []interface{}:
+		m[last] = append(existing, content)
+	default:
+		return fmt.Errorf("conflicting block %q", strings.Join(path, " "))
+	}
+	return nil
+}
+
+func (p *hclParser) parseValue() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '"':
+		return p.parseString()
+	case strings.HasPrefix(p.s[p.pos:], "<<"):
+		return p.parseHeredoc()
+	case c == '[':
+		return p.parseList()
+	case c == '{':
+		return p.parseObject()
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case isHCLIdentChar(c, true):
+		switch ident := p.parseIdent(); ident {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("unsupported expression %q, only literals are supported", ident)
+		}
+	case p.eof():
+		return nil, fmt.Errorf("expected value, got EOF")
+	default:
+		return nil, fmt.Errorf("unexpected %q", c)
+	}
+}
+
+func (p *hclParser) parseList() (interface{}, error) {
+	p.pos++ // '['
+	list := []interface{}{}
+	for {
+		p.skipSpaces(true)
+		if p.peek() == ']' {
+			p.pos++
+			return list, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+		p.skipSpaces(true)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, fmt.Errorf("expected ',' or ']' in list")
+		}
+	}
+}
+
+func (p *hclParser) parseObject() (interface{}, error) {
+	p.pos++ // '{'
+	obj := map[string]interface{}{}
+	for {
+		p.skipSpaces(true)
+		if p.peek() == '}' {
+			p.pos++
+			return obj, nil
+		}
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces(false)
+		if c := p.peek(); c != '=' && c != ':' {
+			return nil, fmt.Errorf("expected '=' after %q", key)
+		}
+		p.pos++
+		p.skipSpaces(false)
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = v
+		p.skipSpaces(false)
+		switch p.peek() {
+		case ',', '\n':
+			p.pos++
+		case '}':
+		default:
+			return nil, fmt.Errorf("expected ',', newline or '}' in object")
+		}
+	}
+}
+
+func (p *hclParser) parseNumber() (interface{}, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	isFloat := false
+	for !p.eof() {
+		c := p.s[p.pos]
+		if c >= '0' && c <= '9' {
+			p.pos++
+		} else if c == '.' || c == 'e' || c == 'E' {
+			isFloat = true
+			p.pos++
+			if (c == 'e' || c == 'E') && (p.peek() == '+' || p.peek() == '-') {
+				p.pos++
+			}
+		} else {
+			break
+		}
+	}
+	s := p.s[start:p.pos]
+	if !isFloat {
+		if n, err := strconv.ParseInt(s, 10, 0); err == nil {
+			return int(n), nil
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	return n, nil
+}
+
+// parseString parses the quoted string, the templates like "${...}" are kept.
+func (p *hclParser) parseString() (string, error) {
+	p.pos++ // '"'
+	var buf []byte
+	for !p.eof() {
+		c := p.s[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return string(buf), nil
+		case c == '\n':
+			return "", fmt.Errorf("unterminated string")
+		case c == '\\':
+			if p.pos+1 >= len(p.s) {
+				return "", fmt.Errorf("unterminated string")
+			}
+			e := p.s[p.pos+1]
+			p.pos += 2
+			switch e {
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case '"', '\\':
+				buf = append(buf, e)
+			case 'u', 'U':
+				size := 4
+				if e == 'U' {
+					size = 8
+				}
+				if p.pos+size > len(p.s) {
+					return "", fmt.Errorf("invalid unicode escape")
+				}
+				n, err := strconv.ParseUint(p.s[p.pos:p.pos+size], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(n)) {
+					return "", fmt.Errorf("invalid unicode escape: \\%c%s", e, p.s[p.pos:p.pos+size])
+				}
+				var tmp [utf8.UTFMax]byte
+				buf = append(buf, tmp[:utf8.EncodeRune(tmp[:], rune(n))]...)
+				p.pos += size
+			default:
+				return "", fmt.Errorf("invalid escape: \\%c", e)
+			}
+		case strings.HasPrefix(p.s[p.pos:], "${"):
+			// Keep the template, it may contain quotes.
+			end := closingBrace(p.s, p.pos+2)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated template")
+			}
+			buf = append(buf, p.s[p.pos:end+1]...)
+			p.pos = end + 1
+		default:
+			buf = append(buf, c)
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// parseHeredoc parses <<EOT or <<-EOT heredoc, the common leading spaces
+// of the lines are removed for the latter.
+func (p *hclParser) parseHeredoc() (interface{}, error) {
+	p.pos += 2
+	indented := p.peek() == '-'
+	if indented {
+		p.pos++
+	}
+	marker := p.parseIdent()
+	if marker == "" {
+		return nil, fmt.Errorf("expected heredoc marker")
+	}
+	p.skipSpaces(false)
+	if p.peek() != '\n' {
+		return nil, fmt.Errorf("expected newline after heredoc marker")
+	}
+	p.pos++
+
+	var lines []string
+	for !p.eof() {
+		end := strings.IndexByte(p.s[p.pos:], '\n')
+		if end < 0 {
+			end = len(p.s) - p.pos
+		}
+		line := strings.TrimSuffix(p.s[p.pos:p.pos+end], "\r")
+		p.pos += end
+		if strings.TrimSpace(line) == marker {
+			if indented {
+				trimIndent(lines)
+			}
+			if len(lines) == 0 {
+				return "", nil
+			}
+			return strings.Join(lines, "\n") + "\n", nil
+		}
+		lines = append(lines, line)
+		p.pos++ // '\n'
+	}
+	return nil, fmt.Errorf("unterminated heredoc %s", marker)
+}
+
+// trimIndent removes the common leading spaces of the non-blank lines.
+func trimIndent(lines []string) {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		} else {
+			lines[i] = strings.TrimLeft(line, " \t")
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package cc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestMergeFromHCL(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"app.hcl": `
+# comment
+name = "app" // comment
+/* block
+   comment */
+port = 8080
+ratio = -1.5e2
+debug = true
+nothing = null
+escaped = "a\tb \"q\" \u00e9 ${env:HOME} ${lookup("k")}"
+ports = [
+  80,
+  443,
+]
+servers = [
+  { host = "a", port = 1 },
+  {
+    host = "b"
+    port = 2
+  },
+]
+database {
+  host = "localhost"
+  replica "r1" {
+    host = "replica"
+  }
+}
+service "web" "v1" {
+  motd = <<-EOT
+    hello
+      world
+  EOT
+  raw = <<EOT
+  kept
+EOT
+}
+upstream { addr = "a" }
+upstream { addr = "b" }
+`,
+	})
+	defer os.RemoveAll(dir)
+
+	c := NewConfig()
+	assert.Must(t, c.MergeFromFile(filepath.Join(dir, "app.hcl")))
+	assert.Check(t, c.String("name"), "app")
+	assert.Check(t, c.Int("port"), 8080)
+	assert.Check(t, c.Float("ratio"), -150.0)
+	assert.Check(t, c.Bool("debug"), true)
+	assert.Check(t, c.Has("nothing"), true)
+	assert.Check(t, c.Raw("escaped"), "a\tb \"q\" \u00e9 ${env:HOME} ${lookup(\"k\")}")
+	assert.Check(t, len(c.Value("ports").List()), 2)
+	assert.Check(t, c.Value("ports").List()[1].Int(), 443)
+
+	servers := c.Value("servers").List()
+	assert.Check(t, len(servers), 2)
+	assert.Check(t, servers[0].Map()["host"].String(), "a")
+	assert.Check(t, servers[1].Map()["port"].Int(), 2)
+
+	assert.Check(t, c.Config("database").String("host"), "localhost")
+	assert.Check(t, c.Config("database").Config("replica").Config("r1").String("host"), "replica")
+	web := c.Config("service").Config("web").Config("v1")
+	assert.Check(t, web.String("motd"), "hello\n  world\n")
+	assert.Check(t, web.String("raw"), "  kept\n")
+
+	upstream := c.Value("upstream").List()
+	assert.Check(t, len(upstream), 2)
+	assert.Check(t, upstream[1].Map()["addr"].String(), "b")
+}
+
+func TestUnmarshalHCLErrors(t *testing.T) {
+	for data, expected := range map[string]string{
+		"a = 1\na = 2":           "line 2: duplicate key \"a\"",
+		"a = 1\na { }":           "line 2: duplicate key \"a\"",
+		"a = var.x":              "line 1: unsupported expression \"var\", only literals are supported",
+		"a = \"x\nb = 1":         "line 1: unterminated string",
+		"a = \"\\q\"":            "line 1: invalid escape: \\q",
+		"a = [1 2]":              "line 1: expected ',' or ']' in list",
+		"a {\n b = 1\n":          "line 3: unclosed block",
+		"}":                      "line 1: unexpected '}'",
+		"a = <<EOT\nx\n":         "line 3: unterminated heredoc EOT",
+		"a \"l\" b = 1":          "line 1: expected '=', label or '{' after \"a\"",
+		"a = {b = 1 c = 2}":      "line 1: expected ',', newline or '}' in object",
+		"a = 1\nb = 1.2.3":       "line 2: invalid number \"1.2.3\"",
+		"a = 1\na \"b\" { }":     "line 2: duplicate key \"a\"",
+		"x = 1\n\n\n= 2":         "line 4: expected identifier, got '='",
+		"a = <<EOT x\nx\nEOT\n":  "line 1: expected newline after heredoc marker",
+		"a = \"${x\"\nb = 1\n":   "line 1: unterminated template",
+		"a = \"\\u12zz\"":        "line 1: invalid unicode escape: \\u12zz",
+		"a = \"\\uD800\"":        "line 1: invalid unicode escape: \\uD800",
+		"a = \"\\U0011FFFF\" ":   "line 1: invalid unicode escape: \\U0011FFFF",
+		"a = \"\\u00e9\\u00e9":   "line 1: unterminated string",
+		"a = -":                  "line 1: invalid number \"-\"",
+		"b = 1\na = ":            "line 2: expected value, got EOF",
+		"a = [1, @]":             "line 1: unexpected '@'",
+		"a = {\"k\" : 1, 2 = 3}": "line 1: expected identifier, got '2'",
+	} {
+		_, err := unmarshalHCL([]byte(data))
+		if err == nil {
+			t.Fatalf("expect error for %q", data)
+		}
+		assert.Check(t, err.Error(), expected)
+	}
+}
@@ -0,0 +1,155 @@
+package cc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The include directives, the value is a path or a list of paths, the paths
+// can be globs and the relative paths are relative to the including file.
+var includeDirectives = []string{"include", "$import"}
+
+// IncludeOrder is the merge order of the included files, see SetIncludeOrder.
+type IncludeOrder int
+
+const (
+	// IncludeBefore merges the included files before the including file,
+	// so the including file overrides the included files, it is the default.
+	IncludeBefore IncludeOrder = iota
+	// IncludeAfter merges the included files after the including file,
+	// so the included files override the including file.
+	IncludeAfter
+)
+
+// SetIncludeOrder sets the merge order of the included files for MergeFromFile.
+func (c *Config) SetIncludeOrder(order IncludeOrder) {
+	c.includeOrder = order
+}
+
+// mergeFromFile merges the file and the files included by it, the including
+// is the absolute paths of the files which are including fpath.
+func (c *Config) mergeFromFile(fsys *fileSystem, fpath string, including []string) error {
+	data, err := c.includeFile(fsys, fpath, including)
+	if err != nil {
+		return err
+	}
+	c.mergeKV(data)
+	return nil
+}
+
+// mergeWithIncludes merges the data and the files included by it, the relative
+// paths are relative to dir.
+func (c *Config) mergeWithIncludes(fsys *fileSystem, data map[string]interface{}, name string, dir string, including []string) error {
+	data, err := c.withIncludes(fsys, data, name, dir, including)
+	if err != nil {
+		return err
+	}
+	c.mergeKV(data)
+	return nil
+}
+
+// includeFile reads the file and merges the files included by it.
+func (c *Config) includeFile(fsys *fileSystem, fpath string, including []string) (map[string]interface{}, error) {
+	abs, err := fsys.abs(fpath)
+	if err != nil {
+		return nil, err
+	}
+	for i, path := range including {
+		if path == abs {
+			return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(including[i:], " -> "), abs)
+		}
+	}
+
+	data, err := c.unmarshalFile(fsys, fpath)
+	if err != nil {
+		return nil, err
+	}
+	dir := fsys.dir(fpath)
+	if fpath == "-" {
+		dir = "."
+	}
+	return c.withIncludes(fsys, data, fpath, dir, append(including, abs))
+}
+
+// withIncludes merges the files included by data and data deeply in the
+// include order, so the sibling keys of the nested maps are kept.
+func (c *Config) withIncludes(fsys *fileSystem, data map[string]interface{}, name string, dir string, including []string) (map[string]interface{}, error) {
+	includes, err := popIncludes(fsys, data, dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if len(includes) == 0 {
+		return data, nil
+	}
+
+	merged := map[string]interface{}{}
+	if c.includeOrder == IncludeAfter {
+		deepMerge(merged, data, "", "", nil)
+	}
+	for _, include := range includes {
+		included, err := c.includeFile(fsys, include, including)
+		if err != nil {
+			return nil, err
+		}
+		deepMerge(merged, included, "", "", nil)
+	}
+	if c.includeOrder == IncludeBefore {
+		deepMerge(merged, data, "", "", nil)
+	}
+	return merged, nil
+}
+
+func (c *Config) mergeKV(data map[string]interface{}) {
+	for k, v := range data {
+		c.kv[k] = v
+	}
+}
+
+// popIncludes removes the include directives from data, returns the
+// included files in order, the globs are expanded in lexical order.
+func popIncludes(fsys *fileSystem, data map[string]interface{}, dir string) ([]string, error) {
+	var files []string
+	for _, directive := range includeDirectives {
+		v, in := data[directive]
+		if !in {
+			continue
+		}
+		delete(data, directive)
+
+		var patterns []string
+		switch x := v.(type) {
+		case string:
+			patterns = []string{x}
+		case []interface{}:
+			for _, e := range x {
+				s, ok := e.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid %s directive: %v", directive, v)
+				}
+				patterns = append(patterns, s)
+			}
+		default:
+			return nil, fmt.Errorf("invalid %s directive: %v", directive, v)
+		}
+
+		for _, pattern := range patterns {
+			if !fsys.isAbs(pattern) {
+				pattern = fsys.join(dir, pattern)
+			}
+			if !hasMeta(pattern) {
+				files = append(files, pattern)
+				continue
+			}
+			matches, err := fsys.glob(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s directive: %v", directive, err)
+			}
+			files = append(files, matches...)
+		}
+	}
+	return files, nil
+}
+
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, `*?[\`)
+}
@@ -0,0 +1,77 @@
+package cc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/damnever/cc/assert"
+)
+
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "cc")
+	assert.Must(t, err)
+	for name, content := range files {
+		fpath := filepath.Join(dir, name)
+		assert.Must(t, os.MkdirAll(filepath.Dir(fpath), 0755))
+		assert.Must(t, ioutil.WriteFile(fpath, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestMergeFromFileInclude(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"base.yaml":         "name: base\nport: 80\nlevel: info\ndb: {host: base, port: 5432}\n",
+		"prod.yaml":         "include: base.yaml\n$import: [secrets/*.yaml, extra.json]\nname: prod\ndb: {host: prod}\n",
+		"secrets/a.yaml":    "password: a\ntoken: a\n",
+		"secrets/b.yaml":    "token: b\n",
+		"secrets/skip.json": `{"token": "skip"}`,
+		"extra.json":        `{"level": "debug", "include": "/nonexistent/*.yaml"}`,
+	})
+	defer os.RemoveAll(dir)
+
+	c := NewConfig()
+	assert.Must(t, c.MergeFromFile(filepath.Join(dir, "prod.yaml")))
+	assert.Check(t, c.String("name"), "prod")
+	assert.Check(t, c.Int("port"), 80)
+	assert.Check(t, c.String("level"), "debug")
+	assert.Check(t, c.String("password"), "a")
+	assert.Check(t, c.String("token"), "b")
+	assert.Check(t, c.Has("include"), false)
+	assert.Check(t, c.Has("$import"), false)
+	assert.Check(t, c.Config("db").String("host"), "prod")
+	assert.Check(t, c.Config("db").Int("port"), 5432)
+
+	c = NewConfig()
+	c.SetIncludeOrder(IncludeAfter)
+	assert.Must(t, c.MergeFromFile(filepath.Join(dir, "prod.yaml")))
+	assert.Check(t, c.String("name"), "base")
+	assert.Check(t, c.Config("db").String("host"), "base")
+	assert.Check(t, c.Config("db").Int("port"), 5432)
+}
+
+func TestMergeFromFileIncludeErrors(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"a.yaml":       "include: b.yaml\n",
+		"b.yaml":       "include: [c.yaml, a.yaml]\n",
+		"c.yaml":       "c: 1\n",
+		"missing.yaml": "include: nope.yaml\n",
+		"invalid.yaml": "include: 1\n",
+	})
+	defer os.RemoveAll(dir)
+
+	c := NewConfig()
+	err := c.MergeFromFile(filepath.Join(dir, "a.yaml"))
+	a, b := filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yaml")
+	assert.Check(t, err.Error(), "include cycle: "+a+" -> "+b+" -> "+a)
+
+	err = c.MergeFromFile(filepath.Join(dir, "missing.yaml"))
+	if !os.IsNotExist(err) {
+		t.Fatalf("expect not exist error, got %v", err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	err = c.MergeFromFile(invalid)
+	assert.Check(t, err.Error(), invalid+": invalid include directive: 1")
+}
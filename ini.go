@@ -0,0 +1,137 @@
+package cc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MergeFromINI merges data from INI bytes, the value from same name will be replaced.
+//
+//	; comment
+//	name = app            # inline comment
+//	[database]
+//	host = localhost
+//	port = 5432
+//	[database.replica]
+//	host: "replica"
+//
+// The sections and the dotted keys become the nested maps, e.g.
+// c.Config("database").Config("replica").String("host"). The values are
+// strings since INI is untyped, e.g. "1.10" and "007" are kept as they are,
+// use CoerceLenient to get the numbers and bools, see SetCoercion. The value of
+// a key which is also a section or the prefix of the other keys is kept under
+// "_value", e.g. "a = 1" and "a.b = 2" become {"a": {"_value": "1", "b": "2"}}.
+func (c *Config) MergeFromINI(b []byte) error {
+	data, err := unmarshalINI(b)
+	if err != nil {
+		return err
+	}
+	c.mergeKV(data)
+	return nil
+}
+
+func unmarshalINI(b []byte) (map[string]interface{}, error) {
+	kv := map[string]interface{}{}
+	var section []string
+	for i, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unclosed section", i+1)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return nil, fmt.Errorf("line %d: empty section name", i+1)
+			}
+			section = splitKey(name)
+			setNested(kv, section, map[string]interface{}{})
+			continue
+		}
+
+		idx := strings.IndexAny(line, "=:")
+		if idx <= 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", i+1)
+		}
+		key := strings.TrimSpace(line[:idx])
+		value, err := parseINIValue(strings.TrimSpace(line[idx+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		path := append(append([]string{}, section...), splitKey(key)...)
+		setNested(kv, path, value)
+	}
+	return kv, nil
+}
+
+// parseINIValue parses the quoted value as string, or strips the inline
+// comment of the unquoted value, the value is always a string.
+func parseINIValue(s string) (interface{}, error) {
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		end := strings.LastIndexByte(s, s[0])
+		if end == 0 {
+			return nil, fmt.Errorf("unclosed quote")
+		}
+		rest := strings.TrimSpace(s[end+1:])
+		if rest != "" && rest[0] != ';' && rest[0] != '#' {
+			return nil, fmt.Errorf("unexpected characters after the quoted value")
+		}
+		if s[0] == '\'' {
+			return s[1:end], nil
+		}
+		return strconv.Unquote(s[:end+1])
+	}
+	for _, comment := range []string{" ;", " #", "\t;", "\t#"} {
+		if idx := strings.Index(s, comment); idx >= 0 {
+			s = strings.TrimSpace(s[:idx])
+		}
+	}
+	return s, nil
+}
+
+// splitKey splits the dotted key into the nested keys.
+func splitKey(key string) []string {
+	keys := strings.Split(key, ".")
+	for i, k := range keys {
+		keys[i] = strings.TrimSpace(k)
+	}
+	return keys
+}
+
+// leafKey is the key of the value whose key is also the prefix of the other
+// keys, e.g. "a" of "a = 1" and "a.b = 2" is {"_value": "1", "b": "2"}.
+const leafKey = "_value"
+
+// setNested sets the value by the nested keys, the intermediate maps are
+// created, the value of a key which is also a map is kept under leafKey.
+func setNested(kv map[string]interface{}, path []string, v interface{}) {
+	m := kv
+	for _, k := range path[:len(path)-1] {
+		switch child := m[k].(type) {
+		case map[string]interface{}:
+			m = child
+			continue
+		case nil:
+			m[k] = map[string]interface{}{}
+		default:
+			m[k] = map[string]interface{}{leafKey: child}
+		}
+		m = m[k].(map[string]interface{})
+	}
+	last := path[len(path)-1]
+	existing, isMap := m[last].(map[string]interface{})
+	child, vIsMap := v.(map[string]interface{})
+	switch {
+	case isMap && vIsMap:
+	case isMap:
+		existing[leafKey] = v
+	case vIsMap && m[last] != nil:
+		child[leafKey] = m[last]
+		m[last] = child
+	default:
+		m[last] = v
+	}
+}
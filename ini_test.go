@@ -0,0 +1,138 @@
+package cc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestMergeFromINI(t *testing.T) {
+	c := NewConfig()
+	assert.Must(t, c.MergeFromINI([]byte(`
+; comment
+# comment
+name = app ; inline comment
+debug = true
+ratio = 0.5
+zip = 01234
+version = 1.10
+code = 007
+
+[database]
+host = localhost
+port: 5432
+password = "p;a#s\"s"
+raw = 'x\n'
+pool.size = 10
+
+[database.replica]
+host = replica
+`)))
+	assert.Check(t, c.String("name"), "app")
+	assert.Check(t, c.String("zip"), "01234")
+	assert.Check(t, c.String("version"), "1.10")
+	assert.Check(t, c.String("code"), "007")
+	assert.Check(t, c.String("debug"), "true")
+	assert.Check(t, c.Bool("debug"), false)
+	c.SetCoercion(CoerceLenient)
+	assert.Check(t, c.Bool("debug"), true)
+	assert.Check(t, c.Float("ratio"), 0.5)
+	assert.Check(t, c.Float("version"), 1.1)
+	assert.Check(t, c.Int("code"), 7)
+	db := c.Config("database")
+	assert.Check(t, db.String("host"), "localhost")
+	assert.Check(t, db.Int("port"), 5432)
+	assert.Check(t, db.String("password"), `p;a#s"s`)
+	assert.Check(t, db.String("raw"), `x\n`)
+	assert.Check(t, db.Config("pool").Int("size"), 10)
+	assert.Check(t, db.Config("replica").String("host"), "replica")
+
+	for data, expected := range map[string]string{
+		"[a":          "line 1: unclosed section",
+		"[]":          "line 1: empty section name",
+		"a":           "line 1: expected key = value",
+		"a = 'x":      "line 1: unclosed quote",
+		"a = \"x\" y": "line 1: unexpected characters after the quoted value",
+	} {
+		err := NewConfig().MergeFromINI([]byte(data))
+		if err == nil {
+			t.Fatalf("expect error for %q", data)
+		}
+		assert.Check(t, err.Error(), expected)
+	}
+}
+
+func TestMergeFromProperties(t *testing.T) {
+	c := NewConfig()
+	assert.Must(t, c.MergeFromProperties([]byte(`
+# comment
+! comment
+database.host = localhost
+database.port: 5432
+database.enabled true
+message = hello \
+          world
+escaped\ key\=x = a\tb\\
+unicode = \u4f60\u597d \uD83D\uDE00
+path=C:\\dir\\
+empty
+`)))
+	c.SetCoercion(CoerceLenient)
+	db := c.Config("database")
+	assert.Check(t, db.String("host"), "localhost")
+	assert.Check(t, db.String("port"), "5432")
+	assert.Check(t, db.Int("port"), 5432)
+	assert.Check(t, db.Bool("enabled"), true)
+	assert.Check(t, c.String("message"), "hello world")
+	assert.Check(t, c.String("escaped key=x"), "a\tb\\")
+	assert.Check(t, c.String("unicode"), "你好 😀")
+	assert.Check(t, c.String("path"), `C:\dir\`)
+	assert.Check(t, c.String("empty"), "")
+
+	err := NewConfig().MergeFromProperties([]byte("a = \\u12"))
+	assert.Check(t, err.Error(), `line 1: invalid unicode escape: \u12`)
+}
+
+func TestMergeFromINIAndPropertiesLeafKey(t *testing.T) {
+	c := NewConfig()
+	assert.Must(t, c.MergeFromProperties([]byte(`
+log4j.rootLogger=DEBUG, A1
+log4j.appender.A1=org.apache.log4j.ConsoleAppender
+log4j.appender.A1.layout=org.apache.log4j.PatternLayout
+log4j.appender.A1.layout.ConversionPattern=%-4r [%t] %-5p %c %x - %m%n
+`)))
+	a1 := c.Config("log4j").Config("appender").Config("A1")
+	assert.Check(t, a1.String("_value"), "org.apache.log4j.ConsoleAppender")
+	assert.Check(t, a1.Config("layout").String("_value"), "org.apache.log4j.PatternLayout")
+	assert.Check(t, a1.Config("layout").String("ConversionPattern"), "%-4r [%t] %-5p %c %x - %m%n")
+	assert.Check(t, c.Config("log4j").String("rootLogger"), "DEBUG, A1")
+
+	for data, expected := range map[string]string{
+		"a = 1\n[a]\nb = 2": "map[a:map[_value:1 b:2]]",
+		"a = 1\na.b = 2":    "map[a:map[_value:1 b:2]]",
+		"a.b = 2\na = 1":    "map[a:map[_value:1 b:2]]",
+		"[a]\nb.c = 1\nb=2": "map[a:map[b:map[_value:2 c:1]]]",
+	} {
+		c := NewConfig()
+		assert.Must(t, c.MergeFromINI([]byte(data)))
+		assert.Check(t, fmt.Sprint(c.KV()), expected)
+	}
+}
+
+func TestMergeFromFileINIAndProperties(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"app.ini":        "[database]\nhost = ini\n",
+		"app.properties": "database.port = 5432\n",
+	})
+	defer os.RemoveAll(dir)
+
+	c := NewConfig()
+	c.SetCoercion(CoerceLenient)
+	assert.Must(t, c.MergeFromFile(filepath.Join(dir, "app.ini")))
+	assert.Check(t, c.Config("database").String("host"), "ini")
+	assert.Must(t, c.MergeFromFile(filepath.Join(dir, "app.properties")))
+	assert.Check(t, c.Config("database").Int("port"), 5432)
+}
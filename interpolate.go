@@ -0,0 +1,284 @@
+package cc
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lookup returns the value by name with the references resolved.
+func (c *Config) lookup(name string) (interface{}, bool) {
+	v, ok, _ := c.lookupE(name)
+	return v, ok
+}
+
+// lookupE is like lookup, but returns the first error of resolving the
+// references too.
+func (c *Config) lookupE(name string) (interface{}, bool, error) {
+	v, ok := c.kv[name]
+	if !ok || !hasReference(v) {
+		return v, ok, nil
+	}
+	r := &resolver{root: c.rootConfig()}
+	if c.root == nil {
+		r.stack = []string{name}
+	}
+	return r.resolve(v), true, r.err
+}
+
+func (c *Config) rootConfig() *Config {
+	if c.root != nil {
+		return c.root
+	}
+	return c
+}
+
+// Resolve returns the Config's internal data with all the references resolved,
+// and the first error, e.g. the referenced key is not found, the file can not
+// be read or the references are cyclic. The unresolvable references are
+// replaced by nil or empty strings.
+// Excludes the flags and environment variables.
+func (c *Config) Resolve() (map[string]interface{}, error) {
+	keys := make([]string, 0, len(c.kv))
+	for k := range c.kv {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys) // for the deterministic errors
+
+	r := &resolver{root: c.rootConfig()}
+	kv := make(map[string]interface{}, len(c.kv))
+	for _, k := range keys {
+		if c.root == nil {
+			r.stack = []string{k}
+		}
+		kv[k] = r.resolve(c.kv[k])
+	}
+	return kv, r.err
+}
+
+// hasReference reports whether v contains any reference, escaped reference
+// or secret reference.
+func hasReference(v interface{}) bool {
+	switch x := v.(type) {
+	case string:
+		return strings.Contains(x, "${") || strings.HasPrefix(x, secretScheme)
+	case Configer:
+		return hasReference(x.KV())
+	case map[string]interface{}:
+		for _, e := range x {
+			if hasReference(e) {
+				return true
+			}
+		}
+	case map[interface{}]interface{}:
+		for _, e := range x {
+			if hasReference(e) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, e := range x {
+			if hasReference(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isTextReference reports whether s is a single environment variable or
+// file reference, e.g. "${env:PORT:-8080}", which is resolved into a string.
+func isTextReference(s string) bool {
+	return (strings.HasPrefix(s, "${env:") || strings.HasPrefix(s, "${file:")) &&
+		closingBrace(s, 2) == len(s)-1
+}
+
+type resolver struct {
+	root *Config
+	// stack is the paths of the references being resolved.
+	stack []string
+	err   error
+}
+
+func (r *resolver) errorf(format string, args ...interface{}) {
+	if r.err == nil {
+		r.err = fmt.Errorf(format, args...)
+	}
+}
+
+func (r *resolver) resolve(v interface{}) interface{} {
+	if !hasReference(v) {
+		if c, ok := v.(Configer); ok {
+			return c.KV()
+		}
+		return v
+	}
+	switch x := v.(type) {
+	case string:
+		return r.resolveString(x)
+	case Configer:
+		return r.resolve(x.KV())
+	case map[interface{}]interface{}:
+		return r.resolve(unknownMapToStringMap(x))
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			m[k] = r.resolve(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(x))
+		for i, e := range x {
+			l[i] = r.resolve(e)
+		}
+		return l
+	}
+	return v
+}
+
+func (r *resolver) resolveString(s string) interface{} {
+	if strings.HasPrefix(s, secretScheme) {
+		secret, err := r.root.resolveSecret(s)
+		if err != nil {
+			r.errorf("%v", err)
+			return nil
+		}
+		return secret
+	}
+	if strings.HasPrefix(s, "${") && closingBrace(s, 2) == len(s)-1 {
+		return r.reference(s[2 : len(s)-1])
+	}
+
+	var buf bytes.Buffer
+	// The string embedding any Secret is a Secret too.
+	secret := false
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "$${"):
+			buf.WriteString("${")
+			i += 3
+		case strings.HasPrefix(s[i:], "${"):
+			end := closingBrace(s, i+2)
+			if end < 0 {
+				r.errorf("unclosed reference in %q", s)
+				buf.WriteString(s[i:])
+				i = len(s)
+				continue
+			}
+			ref := s[i+2 : end]
+			switch v := r.reference(ref).(type) {
+			case nil:
+			case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+				r.errorf("reference %q can not be embedded in a string", ref)
+			case Secret:
+				buf.WriteString(v.Reveal())
+				secret = true
+			default:
+				fmt.Fprintf(&buf, "%v", v)
+			}
+			i = end + 1
+		default:
+			buf.WriteByte(s[i])
+			i++
+		}
+	}
+	if secret {
+		return Secret(buf.String())
+	}
+	return buf.String()
+}
+
+// closingBrace returns the index of the brace which closes the reference
+// starts from start, or -1 if not found, the braces can be nested.
+func closingBrace(s string, start int) int {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (r *resolver) reference(ref string) interface{} {
+	switch {
+	case strings.HasPrefix(ref, "env:"):
+		name, deflt := ref[4:], ""
+		idx := strings.Index(name, ":-")
+		if idx >= 0 {
+			name, deflt = name[:idx], name[idx+2:]
+		}
+		if v := r.root.lookupEnv(name); v != "" || idx < 0 {
+			return v
+		}
+		return r.resolveString(deflt)
+	case strings.HasPrefix(ref, "file:"):
+		data, err := ioutil.ReadFile(ref[5:])
+		if err != nil {
+			r.errorf("reference %q: %v", ref, err)
+			return nil
+		}
+		return strings.TrimRight(string(data), "\r\n")
+	}
+
+	for i, path := range r.stack {
+		if path == ref {
+			r.errorf("reference cycle: %s -> %s", strings.Join(r.stack[i:], " -> "), ref)
+			return nil
+		}
+	}
+	v, ok := lookupPath(r.root.kv, ref)
+	if !ok {
+		r.errorf("reference %q not found", ref)
+		return nil
+	}
+	r.stack = append(r.stack, ref)
+	v = r.resolve(v)
+	r.stack = r.stack[:len(r.stack)-1]
+	return v
+}
+
+// lookupPath finds the value by dotted path, the numbers are list indexes.
+func lookupPath(kv map[string]interface{}, path string) (interface{}, bool) {
+	var v interface{} = kv
+	for _, name := range strings.Split(path, ".") {
+		switch x := v.(type) {
+		case Configer:
+			e, ok := x.KV()[name]
+			if !ok {
+				return nil, false
+			}
+			v = e
+		case map[string]interface{}:
+			e, ok := x[name]
+			if !ok {
+				return nil, false
+			}
+			v = e
+		case map[interface{}]interface{}:
+			e, ok := x[name]
+			if !ok {
+				return nil, false
+			}
+			v = e
+		case []interface{}:
+			i, err := strconv.Atoi(name)
+			if err != nil || i < 0 || i >= len(x) {
+				return nil, false
+			}
+			v = x[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
@@ -0,0 +1,134 @@
+package cc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestInterpolation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cc")
+	assert.Must(t, err)
+	defer os.RemoveAll(dir)
+	secret := filepath.Join(dir, "db")
+	assert.Must(t, ioutil.WriteFile(secret, []byte("s3cret\n"), 0600))
+
+	os.Setenv("CC_TEST_HOME", "/home/cc")
+	defer os.Unsetenv("CC_TEST_HOME")
+
+	c, err := NewConfigFromYAML([]byte(`
+host: localhost
+ports:
+  http: 8080
+  list: [1, 2]
+addr: "${host}:${ports.http}"
+port: "${ports.http}"
+second: "${ports.list.1}"
+ratio: "${ratio_raw}"
+ratio_raw: 0.5
+home: "${env:CC_TEST_HOME}/.cc"
+level: "${env:CC_TEST_LEVEL:-info}"
+nested: "${env:CC_TEST_LEVEL:-${host}}"
+password: "${file:` + secret + `}"
+escaped: "$${host} and $$ and ${host}"
+server: "${ports}"
+child:
+  url: "http://${addr}"
+`))
+	assert.Must(t, err)
+	assert.Check(t, c.String("addr"), "localhost:8080")
+	assert.Check(t, c.Int("port"), 8080)
+	assert.Check(t, c.Int("second"), 2)
+	assert.Check(t, c.Float("ratio"), 0.5)
+	assert.Check(t, c.String("home"), "/home/cc/.cc")
+	assert.Check(t, c.String("level"), "info")
+	assert.Check(t, c.String("nested"), "localhost")
+	assert.Check(t, c.String("password"), "s3cret")
+	assert.Check(t, c.String("escaped"), "${host} and $$ and localhost")
+	assert.Check(t, c.Raw("port"), "${ports.http}")
+	assert.Check(t, c.Value("port").Int(), 8080)
+	assert.Check(t, c.Config("server").Int("http"), 8080)
+	assert.Check(t, c.Value("server").Map()["http"].Int(), 8080)
+	assert.Check(t, c.Config("child").String("url"), "http://localhost:8080")
+	assert.Check(t, c.Value("child").Config().String("url"), "http://localhost:8080")
+
+	// The references are resolved lazily.
+	os.Setenv("CC_TEST_LEVEL", "debug")
+	defer os.Unsetenv("CC_TEST_LEVEL")
+	assert.Check(t, c.String("level"), "debug")
+	assert.Must(t, c.MergeFromYAML([]byte("host: example.com")))
+	assert.Check(t, c.String("addr"), "example.com:8080")
+	assert.Check(t, c.Config("child").String("url"), "http://example.com:8080")
+
+	kv, err := c.Resolve()
+	assert.Must(t, err)
+	assert.Check(t, kv["addr"], "example.com:8080")
+	assert.Check(t, kv["port"], 8080)
+}
+
+func TestInterpolationTextReferenceTypes(t *testing.T) {
+	os.Setenv("CC_TEST_DEBUG", "true")
+	os.Setenv("CC_TEST_ZIP", "007")
+	defer os.Unsetenv("CC_TEST_DEBUG")
+	defer os.Unsetenv("CC_TEST_ZIP")
+
+	c, err := NewConfigFromYAML([]byte(`
+port: ${env:CC_TEST_PORT:-8080}
+ratio: ${env:CC_TEST_RATIO:-0.5}
+debug: ${env:CC_TEST_DEBUG}
+timeout: ${env:CC_TEST_TIMEOUT:-3s}
+zip: ${env:CC_TEST_ZIP}
+ports: ${env:CC_TEST_PORTS:-80,443}
+embedded: "${env:CC_TEST_PORT:-8080}0"
+`))
+	assert.Must(t, err)
+	// The env and file references are strings, but they are converted by
+	// the getters under the default strict coercion.
+	assert.Check(t, c.Int("port"), 8080)
+	assert.Check(t, c.Uint("port"), uint(8080))
+	assert.Check(t, c.String("port"), "8080")
+	assert.Check(t, c.Float("ratio"), 0.5)
+	assert.Check(t, c.Bool("debug"), true)
+	assert.Check(t, c.Duration("timeout"), 3*time.Second)
+	assert.Check(t, c.String("zip"), "007")
+	assert.Check(t, c.Int("zip"), 7)
+	assert.Check(t, fmt.Sprint(c.IntSlice("ports")), "[80 443]")
+	assert.Check(t, c.Value("port").Int(), 8080)
+	assert.Check(t, c.Int("embedded"), 0)
+
+	var s struct {
+		Port int `cc:"port"`
+	}
+	assert.Must(t, c.Decode(&s))
+	assert.Check(t, s.Port, 8080)
+}
+
+func TestInterpolationErrors(t *testing.T) {
+	for data, expected := range map[string]string{
+		"a: ${b}\nb: ${c}\nc: ${a}":  "reference cycle: a -> b -> c -> a",
+		"a: x${a}":                   "reference cycle: a -> a",
+		"a: ${nope}":                 `reference "nope" not found`,
+		"a: ${list.5}\nlist: [1]":    `reference "list.5" not found`,
+		"a: x${m}\nm: {k: v}":        `reference "m" can not be embedded in a string`,
+		"a: ${file:/nonexistent/cc}": `reference "file:/nonexistent/cc": open /nonexistent/cc: no such file or directory`,
+		"a: x${b":                    `unclosed reference in "x${b"`,
+	} {
+		c, err := NewConfigFromYAML([]byte(data))
+		assert.Must(t, err)
+		_, err = c.Resolve()
+		if err == nil {
+			t.Fatalf("expect error for %q", data)
+		}
+		assert.Check(t, err.Error(), expected)
+	}
+
+	c, err := NewConfigFromYAML([]byte("a: ${nope}\nb: x${nope}y"))
+	assert.Must(t, err)
+	assert.Check(t, c.IntOr("a", 3), 3)
+	assert.Check(t, c.String("b"), "xy")
+}
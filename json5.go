@@ -0,0 +1,398 @@
+package cc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MergeFromJSON5 merges data from JSON5 bytes, the value from same name will
+// be replaced. JSON5 is a superset of JSON, so it is for JSON with comments too:
+//
+//	// comment
+//	{
+//	  name: 'app',          /* unquoted keys and single-quoted strings */
+//	  mask: 0xFF,           // hex numbers
+//	  ports: [80, 443,],    // trailing commas
+//	}
+//
+// The numbers are json.Number as JSON, except Infinity and NaN are float64s.
+// The files with the ".jsonc" or ".json5" extension are merged the same way by
+// MergeFromFile, see SetLenientJSON for the ".json" files.
+func (c *Config) MergeFromJSON5(b []byte) error {
+	data, err := unmarshalJSON5(b)
+	if err != nil {
+		return err
+	}
+	c.mergeKV(data)
+	return nil
+}
+
+// SetLenientJSON makes MergeFromFile, MergeFromFS, MergeFromDir and
+// MergeFromReader parse the JSON as JSON5 if lenient is true, so the comments,
+// trailing commas etc. are allowed in the ".json" files, see MergeFromJSON5.
+func (c *Config) SetLenientJSON(lenient bool) {
+	c.lenientJSON = lenient
+}
+
+func unmarshalJSON5(b []byte) (map[string]interface{}, error) {
+	p := &json5Parser{s: strings.TrimPrefix(string(b), "\xef\xbb\xbf")}
+	data, err := p.parse()
+	if err != nil {
+		line := 1 + strings.Count(p.s[:p.pos], "\n")
+		col := 1 + utf8.RuneCountInString(p.s[strings.LastIndexByte(p.s[:p.pos], '\n')+1:p.pos])
+		return nil, fmt.Errorf("%d:%d: %v", line, col, err)
+	}
+	return data, nil
+}
+
+type json5Parser struct {
+	s   string
+	pos int
+}
+
+func (p *json5Parser) parse() (map[string]interface{}, error) {
+	p.skipSpaces()
+	if p.peek() != '{' {
+		return nil, p.unexpected("'{'")
+	}
+	v, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.s) {
+		return nil, p.unexpected("end of input")
+	}
+	return v.(map[string]interface{}), nil
+}
+
+func (p *json5Parser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *json5Parser) unexpected(expected string) error {
+	if p.pos >= len(p.s) {
+		return fmt.Errorf("unexpected end of input, expected %s", expected)
+	}
+	r, _ := utf8.DecodeRuneInString(p.s[p.pos:])
+	return fmt.Errorf("unexpected %q, expected %s", r, expected)
+}
+
+// skipSpaces skips the whitespaces and comments.
+func (p *json5Parser) skipSpaces() {
+	for p.pos < len(p.s) {
+		switch rest := p.s[p.pos:]; {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r' || rest[0] == '\n':
+			p.pos++
+		case strings.HasPrefix(rest, "//"):
+			for p.pos < len(p.s) && p.s[p.pos] != '\n' {
+				p.pos++
+			}
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				return
+			}
+			p.pos += end + 4
+		default:
+			return
+		}
+	}
+}
+
+func (p *json5Parser) parseValue() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '{':
+		return p.parseObject()
+	case c == '[':
+		return p.parseArray()
+	case c == '"' || c == '\'':
+		return p.parseString()
+	case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case isJSON5IdentChar(c, true):
+		start := p.pos
+		switch ident := p.parseIdent(); ident {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		case "Infinity", "NaN":
+			p.pos = start
+			return p.parseNumber()
+		default:
+			p.pos = start
+			return nil, fmt.Errorf("unexpected %q, expected value", ident)
+		}
+	default:
+		return nil, p.unexpected("value")
+	}
+}
+
+func (p *json5Parser) parseObject() (interface{}, error) {
+	p.pos++ // '{'
+	obj := map[string]interface{}{}
+	for {
+		p.skipSpaces()
+		if p.peek() == '}' {
+			p.pos++
+			return obj, nil
+		}
+		var key string
+		var err error
+		if c := p.peek(); c == '"' || c == '\'' {
+			key, err = p.parseString()
+			if err != nil {
+				return nil, err
+			}
+		} else if key = p.parseIdent(); key == "" {
+			return nil, p.unexpected("key or '}'")
+		}
+		p.skipSpaces()
+		if p.peek() != ':' {
+			return nil, p.unexpected("':'")
+		}
+		p.pos++
+		p.skipSpaces()
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = v
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return nil, p.unexpected("',' or '}'")
+		}
+	}
+}
+
+func (p *json5Parser) parseArray() (interface{}, error) {
+	p.pos++ // '['
+	list := []interface{}{}
+	for {
+		p.skipSpaces()
+		if p.peek() == ']' {
+			p.pos++
+			return list, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.unexpected("',' or ']'")
+		}
+	}
+}
+
+func isJSON5IdentChar(c byte, first bool) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+		(!first && c >= '0' && c <= '9')
+}
+
+func (p *json5Parser) parseIdent() string {
+	start := p.pos
+	for p.pos < len(p.s) && isJSON5IdentChar(p.s[p.pos], p.pos == start) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// parseNumber parses the decimal and hex numbers, the leading '+', leading or
+// trailing '.', Infinity and NaN are allowed.
+func (p *json5Parser) parseNumber() (interface{}, error) {
+	start := p.pos
+	sign := 1.0
+	if c := p.peek(); c == '-' || c == '+' {
+		if c == '-' {
+			sign = -1
+		}
+		p.pos++
+	}
+	rest := p.s[p.pos:]
+	switch {
+	case strings.HasPrefix(rest, "Infinity"):
+		p.pos += len("Infinity")
+		return math.Inf(int(sign)), nil
+	case strings.HasPrefix(rest, "NaN"):
+		p.pos += len("NaN")
+		return math.NaN(), nil
+	case strings.HasPrefix(rest, "0x") || strings.HasPrefix(rest, "0X"):
+		p.pos += 2
+		digits := p.pos
+		for p.pos < len(p.s) && strings.IndexByte("0123456789abcdefABCDEF", p.s[p.pos]) >= 0 {
+			p.pos++
+		}
+		n, err := strconv.ParseUint(p.s[digits:p.pos], 16, 64)
+		if err != nil {
+			s := p.s[start:p.pos]
+			p.pos = start
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+		if sign < 0 {
+			return json.Number("-" + strconv.FormatUint(n, 10)), nil
+		}
+		return json.Number(strconv.FormatUint(n, 10)), nil
+	}
+
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if (c >= '0' && c <= '9') || c == '.' {
+			p.pos++
+		} else if c == 'e' || c == 'E' {
+			p.pos++
+			if c := p.peek(); c == '+' || c == '-' {
+				p.pos++
+			}
+		} else {
+			break
+		}
+	}
+	s := p.s[start:p.pos]
+	if _, err := strconv.ParseFloat(strings.TrimPrefix(s, "+"), 64); err != nil {
+		p.pos = start
+		return nil, fmt.Errorf("invalid number %q", s)
+	}
+	return json.Number(normalizeJSON5Number(s)), nil
+}
+
+// normalizeJSON5Number converts the number into the JSON form, e.g. "+.5" to
+// "0.5" and "5." to "5".
+func normalizeJSON5Number(s string) string {
+	s = strings.TrimPrefix(s, "+")
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	if strings.HasPrefix(s, ".") {
+		s = "0" + s
+	}
+	if idx := strings.IndexByte(s, '.'); idx >= 0 && (idx+1 == len(s) || s[idx+1] < '0' || s[idx+1] > '9') {
+		s = s[:idx] + s[idx+1:]
+	}
+	if neg {
+		return "-" + s
+	}
+	return s
+}
+
+// parseString parses the double or single quoted string.
+func (p *json5Parser) parseString() (string, error) {
+	quote := p.s[p.pos]
+	p.pos++
+	var buf []byte
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return string(buf), nil
+		case c == '\n':
+			return "", fmt.Errorf("unterminated string")
+		case c == '\\':
+			p.pos++
+			r, err := p.parseEscape()
+			if err != nil {
+				return "", err
+			}
+			if r >= 0 {
+				var tmp [utf8.UTFMax]byte
+				buf = append(buf, tmp[:utf8.EncodeRune(tmp[:], r)]...)
+			}
+		default:
+			buf = append(buf, c)
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// parseEscape parses the escape after '\', returns -1 for the line continuation.
+func (p *json5Parser) parseEscape() (rune, error) {
+	if p.pos >= len(p.s) {
+		return 0, fmt.Errorf("unterminated string")
+	}
+	c := p.s[p.pos]
+	p.pos++
+	switch c {
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'v':
+		return '\v', nil
+	case '0':
+		return 0, nil
+	case '\n':
+		return -1, nil
+	case '\r':
+		if p.peek() == '\n' {
+			p.pos++
+		}
+		return -1, nil
+	case 'x', 'u':
+		size := 2
+		if c == 'u' {
+			size = 4
+		}
+		r, err := p.parseHex(size)
+		if err != nil {
+			return 0, err
+		}
+		if c == 'u' && utf16.IsSurrogate(r) && strings.HasPrefix(p.s[p.pos:], "\\u") {
+			p.pos += 2
+			r2, err := p.parseHex(4)
+			if err != nil {
+				return 0, err
+			}
+			r = utf16.DecodeRune(r, r2)
+		}
+		return r, nil
+	default:
+		if c >= '1' && c <= '9' {
+			p.pos--
+			return 0, fmt.Errorf("invalid escape: \\%c", c)
+		}
+		// '\'', '"', '\\', '/' and the other characters are themselves.
+		p.pos--
+		r, size := utf8.DecodeRuneInString(p.s[p.pos:])
+		p.pos += size
+		return r, nil
+	}
+}
+
+func (p *json5Parser) parseHex(size int) (rune, error) {
+	if p.pos+size > len(p.s) {
+		return 0, fmt.Errorf("invalid escape")
+	}
+	n, err := strconv.ParseUint(p.s[p.pos:p.pos+size], 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid escape: %q", p.s[p.pos:p.pos+size])
+	}
+	p.pos += size
+	return rune(n), nil
+}
@@ -0,0 +1,103 @@
+package cc
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestUnmarshalJSON5(t *testing.T) {
+	data, err := unmarshalJSON5([]byte("\xef\xbb\xbf" + `// comment
+{
+  /* block
+     comment */
+  name: 'app',
+  "quoted": "a\tb \"q\" é 😀 \x41 \'",
+  $key_1: "line \
+continued",
+  mask: 0xFF,
+  neg: -0x10,
+  nums: [+1, .5, 5., 1e3, -2.5E-1,],
+  inf: -Infinity,
+  nan: NaN,
+  ok: true, no: false, nothing: null,
+  nested: {list: [{a: 1}, {a: 2},],},
+}
+`))
+	assert.Must(t, err)
+	assert.Check(t, data["name"], "app")
+	assert.Check(t, data["quoted"], "a\tb \"q\" é \U0001F600 A '")
+	assert.Check(t, data["$key_1"], "line continued")
+	assert.Check(t, data["mask"], json.Number("255"))
+	assert.Check(t, data["neg"], json.Number("-16"))
+	nums := data["nums"].([]interface{})
+	assert.Check(t, len(nums), 5)
+	for i, expected := range []string{"1", "0.5", "5", "1e3", "-2.5E-1"} {
+		assert.Check(t, nums[i], json.Number(expected))
+	}
+	assert.Check(t, math.IsInf(data["inf"].(float64), -1), true)
+	assert.Check(t, math.IsNaN(data["nan"].(float64)), true)
+	assert.Check(t, data["ok"], true)
+	assert.Check(t, data["no"], false)
+	assert.Check(t, data["nothing"], nil)
+	list := data["nested"].(map[string]interface{})["list"].([]interface{})
+	assert.Check(t, list[1].(map[string]interface{})["a"], json.Number("2"))
+
+	for data, expected := range map[string]string{
+		"[1]":                  "1:1: unexpected '[', expected '{'",
+		"{a: 1,\n  b: }":       "2:6: unexpected '}', expected value",
+		"{a: 1} x":             "1:8: unexpected 'x', expected end of input",
+		"{a 1}":                "1:4: unexpected '1', expected ':'",
+		"{a: [1 2]}":           "1:8: unexpected '2', expected ',' or ']'",
+		"{a: 1 b: 2}":          "1:7: unexpected 'b', expected ',' or '}'",
+		"{\"é\": 'x\n'}":       "1:9: unterminated string",
+		"{a: undefined}":       "1:5: unexpected \"undefined\", expected value",
+		"{a: 1.2.3}":           "1:5: invalid number \"1.2.3\"",
+		"{a: 0xZZ}":            "1:5: invalid number \"0x\"",
+		"{a: '\\u12'}":         "1:8: invalid escape: \"12'}\"",
+		"{a: '\\1'}":           "1:7: invalid escape: \\1",
+		"{-: 1}":               "1:2: unexpected '-', expected key or '}'",
+		"{\n  a: 1,\n  b: [\n": "4:1: unexpected end of input, expected value",
+	} {
+		_, err := unmarshalJSON5([]byte(data))
+		if err == nil {
+			t.Fatalf("expect error for %q", data)
+		}
+		assert.Check(t, err.Error(), expected)
+	}
+}
+
+func TestLenientJSON(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"app.jsonc": "{\n  // comment\n  name: 'jsonc',\n}\n",
+		"app.json5": "{mask: 0x10}",
+		"app.json":  "{\n  \"port\": 80, // comment\n}\n",
+	})
+	defer os.RemoveAll(dir)
+
+	c := NewConfig()
+	assert.Must(t, c.MergeFromFile(filepath.Join(dir, "app.jsonc")))
+	assert.Must(t, c.MergeFromFile(filepath.Join(dir, "app.json5")))
+	assert.Check(t, c.String("name"), "jsonc")
+	assert.Check(t, c.Int("mask"), 16)
+	if err := c.MergeFromFile(filepath.Join(dir, "app.json")); err == nil {
+		t.Fatal("expect error for the strict JSON")
+	}
+
+	c.SetLenientJSON(true)
+	assert.Must(t, c.MergeFromFile(filepath.Join(dir, "app.json")))
+	assert.Check(t, c.Int("port"), 80)
+	assert.Must(t, c.MergeFromReader(strings.NewReader("{a: 1,}"), ""))
+	assert.Must(t, c.MergeFromReader(strings.NewReader("{b: 2,}"), "json"))
+	assert.Check(t, c.Int("a"), 1)
+	assert.Check(t, c.Int("b"), 2)
+
+	c = NewConfig()
+	assert.Must(t, c.MergeFromJSON5([]byte("{c: 'x'}")))
+	assert.Check(t, c.String("c"), "x")
+}
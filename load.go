@@ -0,0 +1,198 @@
+package cc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadOption configures Load.
+type LoadOption func(o *loadOptions)
+
+type loadOptions struct {
+	file        string
+	profiles    []string
+	profileEnv  string
+	profileFlag string
+}
+
+// File sets the base config file of Load, it is "config.yaml" by default.
+func File(fpath string) LoadOption {
+	return func(o *loadOptions) {
+		o.file = fpath
+	}
+}
+
+// Profiles sets the active profiles of Load, the profiles from the flag and
+// the environment variable are ignored.
+func Profiles(names ...string) LoadOption {
+	return func(o *loadOptions) {
+		o.profiles = names
+	}
+}
+
+// ProfileEnv sets the environment variable which has the comma separated
+// active profiles, it is "APP_PROFILE" by default.
+func ProfileEnv(name string) LoadOption {
+	return func(o *loadOptions) {
+		o.profileEnv = name
+	}
+}
+
+// ProfileFlag sets the flag which has the comma separated active profiles,
+// it is "profile" by default, the flag has priority over the environment
+// variable.
+func ProfileFlag(name string) LoadOption {
+	return func(o *loadOptions) {
+		o.profileFlag = name
+	}
+}
+
+// Load loads the base config file and the overlays of the active profiles,
+// e.g. with Profiles("prod", "eu"), the files are loaded in order:
+//
+//	config.yaml
+//	config.prod.yaml
+//	config.eu.yaml
+//
+// The missing overlays are ignored. The "profiles" section in each file has
+// the overlays of the profiles too, which are applied after the file:
+//
+//	port: 80
+//	profiles:
+//	    prod:
+//	        port: 8080
+//
+// The maps are merged deeply, other values are replaced. The file which each
+// value is loaded from is recorded, see Source.
+func Load(opts ...LoadOption) (*Config, error) {
+	o := &loadOptions{
+		file:        "config.yaml",
+		profileEnv:  "APP_PROFILE",
+		profileFlag: "profile",
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	c := NewConfig()
+	c.sources = map[string]string{}
+	profiles := o.profiles
+	if profiles == nil {
+		active, _ := c.flag(o.profileFlag).(string)
+		if active == "" {
+			active = os.Getenv(o.profileEnv)
+		}
+		profiles = splitProfiles(active)
+	}
+
+	if err := c.loadFile(o.file, profiles); err != nil {
+		return nil, err
+	}
+	ext := filepath.Ext(o.file)
+	for _, profile := range profiles {
+		overlay := strings.TrimSuffix(o.file, ext) + "." + profile + ext
+		if _, err := os.Stat(overlay); os.IsNotExist(err) {
+			continue
+		}
+		if err := c.loadFile(overlay, profiles); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func splitProfiles(s string) []string {
+	var profiles []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles
+}
+
+// loadFile merges the file and the sections of the active profiles in it deeply.
+func (c *Config) loadFile(fpath string, profiles []string) error {
+	file := newConfig()
+	file.includeOrder = c.includeOrder
+	file.lenientJSON = c.lenientJSON
+	if err := file.MergeFromFile(fpath); err != nil {
+		return err
+	}
+	sections, _ := toStringMap(file.kv["profiles"])
+	delete(file.kv, "profiles")
+
+	source := filepath.Base(fpath)
+	deepMerge(c.kv, file.kv, "", source, c.sources)
+	for _, profile := range profiles {
+		if section, ok := toStringMap(sections[profile]); ok {
+			deepMerge(c.kv, section, "", source+"#profiles."+profile, c.sources)
+		}
+	}
+	return nil
+}
+
+// Source returns the file which the value by dotted name is loaded from by
+// Load, e.g. "config.prod.yaml" or "config.yaml#profiles.prod" for the
+// profile sections, returns "" if unknown.
+func (c *Config) Source(name string) string {
+	return c.sources[name]
+}
+
+func toStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return x, true
+	case map[interface{}]interface{}:
+		return unknownMapToStringMap(x), true
+	case Configer:
+		return x.KV(), true
+	}
+	return nil, false
+}
+
+// deepMerge merges src into dst, the maps are merged recursively and the
+// sources of the merged paths are recorded if sources is not nil.
+func deepMerge(dst map[string]interface{}, src map[string]interface{}, prefix string, source string, sources map[string]string) {
+	for k, v := range src {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if sources != nil {
+			sources[path] = source
+		}
+
+		srcMap, srcOk := toStringMap(v)
+		dstMap, dstOk := toStringMap(dst[k])
+		if !srcOk || !dstOk {
+			for p := range sources {
+				if strings.HasPrefix(p, path+".") {
+					delete(sources, p)
+				}
+			}
+			if srcOk && sources != nil {
+				recordSources(srcMap, path, source, sources)
+			}
+			dst[k] = v
+			continue
+		}
+		merged := make(map[string]interface{}, len(dstMap)+len(srcMap))
+		for dk, dv := range dstMap {
+			merged[dk] = dv
+		}
+		deepMerge(merged, srcMap, path, source, sources)
+		dst[k] = merged
+	}
+}
+
+func recordSources(m map[string]interface{}, prefix string, source string, sources map[string]string) {
+	for k, v := range m {
+		path := prefix + "." + k
+		sources[path] = source
+		if child, ok := toStringMap(v); ok {
+			recordSources(child, path, source, sources)
+		}
+	}
+}
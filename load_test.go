@@ -0,0 +1,66 @@
+package cc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"config.yaml": `
+name: app
+db:
+  host: localhost
+  port: 5432
+profiles:
+  prod:
+    db:
+      host: db.prod
+  eu:
+    region: eu-west-1
+`,
+		"config.prod.yaml": "db:\n  pool: 10\nlevel: warn\n",
+		"config.eu.yaml":   "profiles:\n  prod:\n    level: error\n",
+	})
+	defer os.RemoveAll(dir)
+	fpath := filepath.Join(dir, "config.yaml")
+
+	c, err := Load(File(fpath), Profiles("prod", "eu"))
+	assert.Must(t, err)
+	assert.Check(t, c.String("name"), "app")
+	db := c.Config("db")
+	assert.Check(t, db.String("host"), "db.prod")
+	assert.Check(t, db.Int("port"), 5432)
+	assert.Check(t, db.Int("pool"), 10)
+	assert.Check(t, c.String("region"), "eu-west-1")
+	assert.Check(t, c.String("level"), "error")
+	assert.Check(t, c.Has("profiles"), false)
+
+	assert.Check(t, c.Source("name"), "config.yaml")
+	assert.Check(t, c.Source("db.host"), "config.yaml#profiles.prod")
+	assert.Check(t, c.Source("db.port"), "config.yaml")
+	assert.Check(t, c.Source("db.pool"), "config.prod.yaml")
+	assert.Check(t, c.Source("region"), "config.yaml#profiles.eu")
+	assert.Check(t, c.Source("level"), "config.eu.yaml#profiles.prod")
+	assert.Check(t, c.Source("nope"), "")
+
+	os.Setenv("CC_TEST_PROFILE", " eu ")
+	defer os.Unsetenv("CC_TEST_PROFILE")
+	c, err = Load(File(fpath), ProfileEnv("CC_TEST_PROFILE"))
+	assert.Must(t, err)
+	assert.Check(t, c.Config("db").String("host"), "localhost")
+	assert.Check(t, c.String("region"), "eu-west-1")
+	assert.Check(t, c.Has("level"), false)
+
+	c, err = Load(File(fpath))
+	assert.Must(t, err)
+	assert.Check(t, c.Has("region"), false)
+
+	_, err = Load(File(filepath.Join(dir, "nope.yaml")))
+	if !os.IsNotExist(err) {
+		t.Fatalf("expect not exist error, got %v", err)
+	}
+}
@@ -1,38 +1,182 @@
 package cc
 
 import (
+	"fmt"
+	"path"
 	"regexp"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/damnever/cc/rpn"
 )
 
 // Pattern implements the Patterner interface.
+// A Pattern is immutable once created, so it is safe for concurrent use.
 type Pattern struct {
 	pattern string
-	re      *regexp.Regexp
+	kind    patternKind
+	// match validates the string, it is the regular expression or
+	// the prefixed string pattern, e.g. "enum:debug|info".
+	match   func(s string) bool
 	rpn     *rpn.ReversePolishNotation
-	err     error
+	reErr   error
+	condErr error
 	badRe   bool
 	badCond bool
 }
 
+type patternKind uint8
+
+const (
+	kindRegexp patternKind = iota
+	kindCond
+	kindEnum
+	kindGlob
+	kindLen
+	kindFormat
+)
+
+// prefixes are the prefixes of the string patterns besides the regular expression.
+var prefixes = map[string]patternKind{
+	"enum:":   kindEnum,
+	"glob:":   kindGlob,
+	"len:":    kindLen,
+	"format:": kindFormat,
+}
+
 // NewPattern creates a new Pattern, even if pattern is not valid,
 // in such case, Validate-like methods always return false.
-// The compiled pattern is cached.
+// The compiled pattern is cached, see SetPatternCacheSize.
 func NewPattern(pattern string) *Pattern {
-	return &Pattern{
-		pattern: pattern,
-		re:      nil,
-		rpn:     nil,
-		err:     nil,
-		badRe:   false,
-		badCond: false,
+	return patterns.get(pattern)
+}
+
+func newPattern(pattern string) *Pattern {
+	p := &Pattern{pattern: pattern}
+	for prefix, kind := range prefixes {
+		if strings.HasPrefix(pattern, prefix) {
+			p.kind = kind
+			p.match, p.reErr = compileStringPattern(kind, pattern[len(prefix):])
+			p.condErr = fmt.Errorf("'%v' is not a condition", pattern)
+			p.badRe = p.reErr != nil
+			p.badCond = true
+			return p
+		}
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err == nil {
+		p.match = re.MatchString
+	}
+	p.reErr = err
+	p.rpn, p.condErr = compileCond(pattern)
+	p.badRe = p.reErr != nil
+	p.badCond = p.condErr != nil
+	if !p.badCond || looksLikeCondition(pattern) {
+		p.kind = kindCond
+	}
+	return p
+}
+
+func compileStringPattern(kind patternKind, pattern string) (func(string) bool, error) {
+	switch kind {
+	case kindEnum:
+		values := strings.Split(pattern, "|")
+		return func(s string) bool {
+			for _, v := range values {
+				if s == v {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case kindGlob:
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("'%v' is not a valid glob: %v", pattern, err)
+		}
+		return func(s string) bool {
+			ok, _ := path.Match(pattern, s)
+			return ok
+		}, nil
+	case kindLen:
+		cond, err := compileCond(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return func(s string) bool {
+			ok, err := cond.Calculate(float64(utf8.RuneCountInString(s)))
+			return err == nil && ok
+		}, nil
+	case kindFormat:
+		if match, ok := formats[pattern]; ok {
+			return match, nil
+		}
+		return nil, fmt.Errorf("unknown format: %v", pattern)
+	}
+	return nil, fmt.Errorf("unknown pattern: %v", pattern)
+}
+
+// CompilePattern compiles the pattern eagerly, it detects whether the pattern
+// is a regular expression or a numeric condition, returns the error if the
+// pattern is invalid for the detected kind.
+func CompilePattern(pattern string) (*Pattern, error) {
+	p := NewPattern(pattern)
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// MustCompilePattern is like CompilePattern but panics if the pattern is invalid.
+func MustCompilePattern(pattern string) *Pattern {
+	p, err := CompilePattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func compileCond(pattern string) (*rpn.ReversePolishNotation, error) {
+	cond, err := rpn.New(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if !cond.IsCondition() {
+		return nil, fmt.Errorf("'%v' is not a condition", pattern)
+	}
+	return cond, nil
+}
+
+// looksLikeCondition reports whether the pattern consists of the condition
+// tokens only and has the placeholder 'N' followed by a comparison, "in" or
+// '?', e.g. "N>20&&N<=", the others such as "N" and "1{3}" are regexps.
+func looksLikeCondition(pattern string) bool {
+	i := strings.IndexByte(pattern, 'N')
+	if i < 0 {
+		return false
 	}
+	for _, c := range pattern {
+		if !strings.ContainsRune("N0123456789.+-*/%<>=!&|()?:,[]{} innot", c) {
+			return false
+		}
+	}
+	rest := pattern[i+1:]
+	return strings.ContainsAny(rest, "<>?") || strings.Contains(rest, "==") ||
+		strings.Contains(rest, "!=") || strings.Contains(rest, "in")
+}
+
+// IsCondition returns true if the pattern is a numeric condition,
+// otherwise it is a string pattern.
+func (p *Pattern) IsCondition() bool {
+	return p.kind == kindCond
 }
 
 // Err returns the error if pattern is wrong.
 func (p *Pattern) Err() error {
-	return p.err
+	if p.kind == kindCond {
+		return p.condErr
+	}
+	return p.reErr
 }
 
 // ValidateInt validate the int value n, return true if it is valid.
@@ -40,27 +184,46 @@ func (p *Pattern) ValidateInt(n int) bool {
 	return p.ValidateFloat(float64(n))
 }
 
+// ValidateIntE validate the int value n, returns a *ValidationError
+// describing why n is not valid.
+func (p *Pattern) ValidateIntE(n int) error {
+	return p.validateFloatE(n, float64(n))
+}
+
 // ValidateFloat validate the float64 value n, return true if it is valid.
 func (p *Pattern) ValidateFloat(n float64) bool {
 	if p.badCond {
 		return false
 	}
+	res, err := p.rpn.Calculate(n)
+	return err == nil && res
+}
 
-	if p.rpn == nil {
-		rpn, err := rpn.New(p.pattern)
-		if err != nil {
-			p.err = err
-			p.badCond = true
-			return false
-		}
-		p.rpn = rpn
+// ValidateFloatE validate the float64 value n, returns a *ValidationError
+// describing why n is not valid.
+func (p *Pattern) ValidateFloatE(n float64) error {
+	return p.validateFloatE(n, n)
+}
+
+func (p *Pattern) validateFloatE(value interface{}, n float64) error {
+	if p.badCond {
+		return &ValidationError{Value: value, Pattern: p.String(), Err: p.condErr}
 	}
 	res, err := p.rpn.Calculate(n)
-	if err != nil {
-		p.err = err
-		p.badCond = true
+	if err != nil || !res {
+		return &ValidationError{Value: value, Pattern: p.String(), Err: err}
 	}
-	return res
+	return nil
+}
+
+// String returns the normalized expression if pattern is a valid
+// condition, e.g. "N>=30&&N<=80" becomes "N >= 30 && N <= 80",
+// otherwise the pattern itself.
+func (p *Pattern) String() string {
+	if p.rpn != nil {
+		return p.rpn.String()
+	}
+	return p.pattern
 }
 
 // ValidateString validate the string value n, return true if it is valid.
@@ -68,15 +231,47 @@ func (p *Pattern) ValidateString(s string) bool {
 	if p.badRe {
 		return false
 	}
+	return p.match(s)
+}
 
-	if p.re == nil {
-		re, err := regexp.Compile(p.pattern)
-		if err != nil {
-			p.err = err
-			p.badRe = true
-			return false
-		}
-		p.re = re
+// ValidateStringE validate the string value s, returns a *ValidationError
+// describing why s is not valid.
+func (p *Pattern) ValidateStringE(s string) error {
+	if p.badRe {
+		return &ValidationError{Value: s, Pattern: p.pattern, Err: p.reErr}
+	}
+	if !p.match(s) {
+		return &ValidationError{Value: s, Pattern: p.pattern}
 	}
-	return p.re.MatchString(s)
+	return nil
+}
+
+// ValidationError describes why a value failed to satisfy a pattern.
+type ValidationError struct {
+	// Value is the value being validated.
+	Value interface{}
+	// Pattern is the normalized pattern.
+	Pattern string
+	// Err is not nil if the pattern is invalid or can not be calculated
+	// with the value, e.g. divide by zero.
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	value := fmt.Sprintf("%v", e.Value)
+	if s, ok := e.Value.(string); ok {
+		value = fmt.Sprintf("%q", s)
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("%s can not be validated by %s: %v", value, e.Pattern, e.Err)
+	}
+	if _, ok := e.Value.(string); ok {
+		return fmt.Sprintf("%s does not match %s", value, e.Pattern)
+	}
+	return fmt.Sprintf("%s does not satisfy %s", value, e.Pattern)
+}
+
+// Unwrap returns the underlying error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
 }
@@ -1,6 +1,7 @@
 package cc
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/damnever/cc/assert"
@@ -54,3 +55,149 @@ func TestPatternValidateFloat(t *testing.T) {
 	}
 
 }
+
+func TestPatternMembership(t *testing.T) {
+	{
+		p := NewPattern("N in [30, 80]")
+		assert.Check(t, p.ValidateInt(30), true)
+		assert.Check(t, p.ValidateInt(80), true)
+		assert.Check(t, p.ValidateInt(81), false)
+		assert.Check(t, p.String(), "N in [30, 80]")
+	}
+	{
+		p := NewPattern("N in (0, 1]")
+		assert.Check(t, p.ValidateFloat(0), false)
+		assert.Check(t, p.ValidateFloat(0.5), true)
+		assert.Check(t, p.ValidateFloat(1), true)
+	}
+	{
+		p := NewPattern("N not in {1,2,5}")
+		assert.Check(t, p.ValidateInt(3), true)
+		assert.Check(t, p.ValidateInt(5), false)
+		assert.Check(t, p.String(), "N not in {1, 2, 5}")
+	}
+	{
+		p := NewPattern("N>100?N%10==0:N>0")
+		assert.Check(t, p.ValidateInt(110), true)
+		assert.Check(t, p.ValidateInt(111), false)
+		assert.Check(t, p.ValidateInt(11), true)
+		assert.Check(t, p.String(), "N > 100 ? N % 10 == 0 : N > 0")
+	}
+	{
+		p := NewPattern("^[a-z]+$")
+		assert.Check(t, p.String(), "^[a-z]+$")
+	}
+}
+
+func TestCompilePattern(t *testing.T) {
+	{
+		p, err := CompilePattern("N>=50")
+		assert.Must(t, err)
+		assert.Check(t, p.IsCondition(), true)
+		assert.Check(t, p.ValidateInt(50), true)
+	}
+	{
+		p, err := CompilePattern("^[a-z]+$")
+		assert.Must(t, err)
+		assert.Check(t, p.IsCondition(), false)
+		assert.Check(t, p.ValidateString("cc"), true)
+	}
+	for _, s := range []string{"N>20&&N<=", "N+1>", "N in [2, 1]", "N ? 1", "^[^12&%*"} {
+		if _, err := CompilePattern(s); err == nil {
+			t.Fatalf("expect error for %q, got nothing", s)
+		}
+	}
+	for s, matched := range map[string]string{"N": "N", "N+1": "NN1", "[0-9]+": "42", "(on|off)": "on", "1{3}": "111"} {
+		p, err := CompilePattern(s)
+		assert.Must(t, err)
+		assert.Check(t, p.IsCondition(), false)
+		assert.Check(t, p.ValidateString(matched), true)
+	}
+	defer func() {
+		if err := recover(); err == nil {
+			t.Fatal("expect panic, got nothing")
+		}
+	}()
+	MustCompilePattern("N>")
+}
+
+func TestPatternValidateE(t *testing.T) {
+	p := NewPattern("N>=50")
+	assert.Check(t, p.ValidateIntE(50), nil)
+	assert.Check(t, p.ValidateIntE(40).Error(), "40 does not satisfy N >= 50")
+	assert.Check(t, p.ValidateFloatE(40.5).Error(), "40.5 does not satisfy N >= 50")
+
+	p = NewPattern("^c")
+	assert.Check(t, p.ValidateStringE("cc"), nil)
+	assert.Check(t, p.ValidateStringE("xx").Error(), `"xx" does not match ^c`)
+
+	p = NewPattern("1/N>0")
+	err := p.ValidateIntE(0)
+	verr, ok := err.(*ValidationError)
+	assert.Check(t, ok, true)
+	if verr.Err == nil {
+		t.Fatal("expect divide by zero error, got nothing")
+	}
+
+	p = NewPattern("^[")
+	if err := p.ValidateStringE("x"); err == nil || err.(*ValidationError).Err == nil {
+		t.Fatalf("expect error of invalid pattern, got %v", err)
+	}
+}
+
+func TestPatternConcurrency(t *testing.T) {
+	p := MustCompilePattern("N in [30, 80]")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				assert.Check(t, p.ValidateInt(n), n >= 30 && n <= 80)
+				assert.Check(t, p.ValidateIntE(n) == nil, n >= 30 && n <= 80)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestPatternStringKinds(t *testing.T) {
+	{
+		p := NewPattern("enum:debug|info|warn")
+		assert.Must(t, p.Err())
+		assert.Check(t, p.IsCondition(), false)
+		assert.Check(t, p.ValidateString("info"), true)
+		assert.Check(t, p.ValidateString("error"), false)
+		assert.Check(t, p.ValidateInt(1), false)
+		assert.Check(t, p.ValidateStringE("error").Error(), `"error" does not match enum:debug|info|warn`)
+	}
+	{
+		p := NewPattern("glob:*.example.com")
+		assert.Must(t, p.Err())
+		assert.Check(t, p.ValidateString("www.example.com"), true)
+		assert.Check(t, p.ValidateString("example.com"), false)
+	}
+	{
+		p := NewPattern("len:N>=8&&N<=64")
+		assert.Must(t, p.Err())
+		assert.Check(t, p.ValidateString("12345678"), true)
+		assert.Check(t, p.ValidateString("一二三四五六七八"), true)
+		assert.Check(t, p.ValidateString("1234567"), false)
+	}
+	for _, s := range []string{"glob:[", "len:N>", "len:N+1", "format:unknown"} {
+		p := NewPattern(s)
+		if p.Err() == nil {
+			t.Fatalf("expect error for %q, got nothing", s)
+		}
+		assert.Check(t, p.ValidateString(""), false)
+	}
+
+	c := NewConfig()
+	c.Set("level", "info")
+	c.Set("host", "www.example.com")
+	level, ok := c.StringAnd("level", "enum:debug|info|warn")
+	assert.Check(t, ok, true)
+	assert.Check(t, level, "info")
+	assert.Check(t, c.StringAndOr("host", "format:ipv4", "127.0.0.1"), "127.0.0.1")
+	assert.Check(t, c.Value("host").StringAndOr("format:hostname", "localhost"), "www.example.com")
+}
@@ -0,0 +1,142 @@
+package cc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MergeFromProperties merges data from Java properties bytes, the value from
+// same name will be replaced.
+//
+//	# comment
+//	! comment
+//	database.host = localhost
+//	database.port: 5432
+//	message = hello \
+//	          world
+//	unicode = 你好
+//
+// The dotted keys become the nested maps, e.g. c.Config("database").String("host").
+// The values are strings, use CoerceLenient to get the numbers and bools, see
+// SetCoercion. The value of a key which is also the prefix of the other keys
+// is kept under "_value", e.g. log4j.appender.A1 and log4j.appender.A1.layout.
+func (c *Config) MergeFromProperties(b []byte) error {
+	data, err := unmarshalProperties(b)
+	if err != nil {
+		return err
+	}
+	c.mergeKV(data)
+	return nil
+}
+
+func unmarshalProperties(b []byte) (map[string]interface{}, error) {
+	kv := map[string]interface{}{}
+	lines := strings.Split(strings.Replace(string(b), "\r\n", "\n", -1), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineno := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		// The line ends with odd backslashes continues.
+		for endsWithContinuation(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		if endsWithContinuation(line) {
+			line = line[:len(line)-1]
+		}
+
+		rawKey, rawValue := splitProperty(line)
+		key, err := unescapeProperty(rawKey)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineno, err)
+		}
+		value, err := unescapeProperty(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineno, err)
+		}
+		setNested(kv, splitKey(key), value)
+	}
+	return kv, nil
+}
+
+func endsWithContinuation(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitProperty splits the line by the first unescaped '=', ':' or whitespace.
+func splitProperty(line string) (string, string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+			end = i
+			break
+		}
+	}
+	key, rest := line[:end], strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return key, rest
+}
+
+// unescapeProperty unescapes \t, \n, \r, \f, \uXXXX and \c which is c itself.
+func unescapeProperty(s string) (string, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	buf := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			buf = append(buf, s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			buf = append(buf, '\t')
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		case 'f':
+			buf = append(buf, '\f')
+		case 'u':
+			if i+4 >= len(s) {
+				return "", fmt.Errorf("invalid unicode escape: %s", s[i-1:])
+			}
+			n, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape: %s", s[i-1:i+5])
+			}
+			i += 4
+			r := rune(n)
+			// The surrogate pair, e.g. \uD83D\uDE00.
+			if utf16.IsSurrogate(r) && i+6 < len(s) && s[i+1] == '\\' && s[i+2] == 'u' {
+				if low, err := strconv.ParseUint(s[i+3:i+7], 16, 16); err == nil {
+					if pair := utf16.DecodeRune(r, rune(low)); pair != utf8.RuneError {
+						r = pair
+						i += 6
+					}
+				}
+			}
+			var tmp [utf8.UTFMax]byte
+			buf = append(buf, tmp[:utf8.EncodeRune(tmp[:], r)]...)
+		default:
+			buf = append(buf, s[i])
+		}
+	}
+	return string(buf), nil
+}
@@ -1,4 +1,6 @@
 // Package rpn defines a kind of condition pattern just like normal if condition in Golang,
 // which trasfer string pattern to normal if condition. e.g. "N>0.3&&N<=0.8".
+// Ranges, sets and ternary conditions are also supported, e.g. "N in (0.3, 0.8]",
+// "N not in {1, 2, 5}" and "N>1 ? N<3 : N>5".
 // Bit operation is not supported.
 package rpn
@@ -1,7 +1,6 @@
 package rpn
 
 import (
-	"container/list"
 	"errors"
 	"fmt"
 	"strconv"
@@ -10,6 +9,7 @@ import (
 
 var numbers = "0123456789"
 var priorities = map[string]uint8{
+	"?:": 0,
 	"||": 1,
 	"&&": 1,
 	">":  2,
@@ -25,12 +25,36 @@ var priorities = map[string]uint8{
 	"/":  4,
 }
 
+// priority returns the priority of op, the set membership operators,
+// e.g. "in[1,2]" or "!in{1,2}", have the same priority as comparisons.
+func priority(op string) uint8 {
+	if isMembership(op) {
+		return 2
+	}
+	return priorities[op]
+}
+
+func isMembership(op string) bool {
+	return strings.HasPrefix(op, "in") || strings.HasPrefix(op, "!in")
+}
+
 // ReversePolishNotation represents a reverse polish notation.
 type ReversePolishNotation struct {
 	notation []string
+	program  []instruction
+	isCond   bool
 }
 
 // New creates a new ReversePolishNotation with a string pattern.
+//
+// Besides the arithmetic, comparison and logical operators, the following
+// syntax is supported:
+//
+//	"N in [30, 80]"    closed range, same as "N>=30&&N<=80"
+//	"N in (0, 1]"      half-open range, same as "N>0&&N<=1"
+//	"N in {1, 2, 5}"   set membership
+//	"N not in {1, 2}"  negative set membership
+//	"N>100 ? N%10==0 : N>0"  ternary condition, right associative
 func New(s string) (*ReversePolishNotation, error) {
 	nop, n := 0, len(s)
 	operators := make([]string, n)
@@ -54,7 +78,7 @@ func New(s string) (*ReversePolishNotation, error) {
 		case ' ':
 			i++
 		case ')':
-			for nop > 0 && operators[nop-1] != "(" {
+			for nop > 0 && operators[nop-1] != "(" && operators[nop-1] != "?" {
 				nop--
 				notation = append(notation, operators[nop])
 			}
@@ -73,7 +97,30 @@ func New(s string) (*ReversePolishNotation, error) {
 			i++
 		case '*', '/', '%', '+', '-':
 			popPushOp(string(c))
+		case '?':
+			// The ternary operator is right associative, only pops
+			// the operators which have higher priority.
+			for nop > 0 && priorities[operators[nop-1]] > 0 && operators[nop-1] != "(" && operators[nop-1] != "!" {
+				nop--
+				notation = append(notation, operators[nop])
+			}
+			operators[nop] = "?"
+			nop++
+			i++
+		case ':':
+			for nop > 0 && operators[nop-1] != "?" && operators[nop-1] != "(" {
+				nop--
+				notation = append(notation, operators[nop])
+			}
+			if nop == 0 || operators[nop-1] != "?" {
+				return nil, fmt.Errorf("'%v' has no '?' found for ':' at %v", s, i)
+			}
+			operators[nop-1] = "?:"
+			i++
 		case '!':
+			if i+1 >= n {
+				return nil, fmt.Errorf("'%v' has invalid token at %v: %v", s, i, c)
+			}
 			next := s[i+1]
 			if next == '(' {
 				operators[nop] = string(c)
@@ -87,12 +134,15 @@ func New(s string) (*ReversePolishNotation, error) {
 			}
 		case '>', '<':
 			op := []byte{c}
-			if s[i+1] == '=' {
+			if i+1 < n && s[i+1] == '=' {
 				op = append(op, '=')
 				i++
 			}
 			popPushOp(string(op))
 		case '|', '&', '=':
+			if i+1 >= n {
+				return nil, fmt.Errorf("'%v' has invalid token at %v: %v", s, i, c)
+			}
 			next := s[i+1]
 			if next != c {
 				return nil, fmt.Errorf("'%v' has invalid token at %v: %v", s, i+1, next)
@@ -100,38 +150,168 @@ func New(s string) (*ReversePolishNotation, error) {
 			i++
 			popPushOp(string([]byte{c, next}))
 		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-			hasDot := false
-			num := []byte{}
-			for i < n {
-				c = s[i]
-				if c == '.' {
-					if hasDot || len(num) == 0 {
-						return nil, fmt.Errorf("'%v' has invalid token at %v: %v", s, i, c)
-					}
-					hasDot = true
-				} else if !strings.Contains(numbers, string(c)) {
-					break
-				}
-				num = append(num, c)
-				i++
+			num, next, err := parseNumber(s, i)
+			if err != nil {
+				return nil, err
 			}
-			notation = append(notation, string(num))
+			notation = append(notation, num)
+			i = next
 		case 'N':
 			notation = append(notation, "N")
 			i++
 		default:
-			return nil, fmt.Errorf("'%v' has invalid token at %v: %v", s, i, c)
+			if c < 'a' || c > 'z' {
+				return nil, fmt.Errorf("'%v' has invalid token at %v: %v", s, i, c)
+			}
+			word, next := parseWord(s, i)
+			negative := false
+			if word == "not" {
+				negative = true
+				if word, next = parseWord(s, skipSpaces(s, next)); word != "in" {
+					return nil, fmt.Errorf("'%v' expects 'in' after 'not' at %v", s, next)
+				}
+			}
+			if word != "in" {
+				return nil, fmt.Errorf("'%v' has invalid token at %v: %v", s, i, word)
+			}
+			op, next, err := parseMembership(s, skipSpaces(s, next))
+			if err != nil {
+				return nil, err
+			}
+			if negative {
+				op = "!" + op
+			}
+			// The membership operator is postfix, emits it right away.
+			for nop > 0 && priorities[operators[nop-1]] >= priority(op) {
+				nop--
+				notation = append(notation, operators[nop])
+			}
+			notation = append(notation, op)
+			i = next
 		}
 	}
 
 	for nop > 0 {
 		nop--
-		if op := operators[nop]; op != "(" {
+		switch op := operators[nop]; op {
+		case "(":
+		case "?":
+			return nil, fmt.Errorf("'%v' has no ':' found for '?'", s)
+		default:
 			notation = append(notation, op)
 		}
 	}
 
-	return &ReversePolishNotation{notation: notation}, nil
+	program, err := compile(notation)
+	if err != nil {
+		return nil, err
+	}
+	isCond, err := check(program)
+	if err != nil {
+		return nil, fmt.Errorf("'%v' is not a valid expression: %v", s, err)
+	}
+	program = branch(program)
+	return &ReversePolishNotation{notation: notation, program: program, isCond: isCond}, nil
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+func parseWord(s string, i int) (string, int) {
+	start := i
+	for i < len(s) && s[i] >= 'a' && s[i] <= 'z' {
+		i++
+	}
+	return s[start:i], i
+}
+
+// parseNumber parses the number literal starts at i, a leading '-' is
+// accepted, returns the literal and the index after it.
+func parseNumber(s string, i int) (string, int, error) {
+	n := len(s)
+	hasDot := false
+	num := []byte{}
+	if i < n && s[i] == '-' {
+		num = append(num, '-')
+		i++
+	}
+	for i < n {
+		c := s[i]
+		if c == '.' {
+			if hasDot || len(num) == 0 || num[len(num)-1] == '-' {
+				return "", i, fmt.Errorf("'%v' has invalid token at %v: %v", s, i, c)
+			}
+			hasDot = true
+		} else if !strings.Contains(numbers, string(c)) {
+			break
+		}
+		num = append(num, c)
+		i++
+	}
+	if len(num) == 0 || num[len(num)-1] == '-' || num[len(num)-1] == '.' {
+		return "", i, fmt.Errorf("'%v' expects a number at %v", s, i)
+	}
+	return string(num), i, nil
+}
+
+// parseMembership parses the range or set starts at i, such as "[30, 80]",
+// "(0, 1]" or "{1, 2, 5}", returns the normalized operator, e.g. "in[30,80]",
+// and the index after it.
+func parseMembership(s string, i int) (string, int, error) {
+	if i >= len(s) {
+		return "", i, fmt.Errorf("'%v' expects a range or set after 'in'", s)
+	}
+	open := s[i]
+	var closes string
+	switch open {
+	case '[', '(':
+		closes = "])"
+	case '{':
+		closes = "}"
+	default:
+		return "", i, fmt.Errorf("'%v' expects a range or set at %v: %v", s, i, open)
+	}
+
+	elems := []string{}
+	i++
+	for {
+		i = skipSpaces(s, i)
+		num, next, err := parseNumber(s, i)
+		if err != nil {
+			return "", next, err
+		}
+		elems = append(elems, num)
+		i = skipSpaces(s, next)
+		if i >= len(s) {
+			return "", i, fmt.Errorf("'%v' has unclosed '%c'", s, open)
+		}
+		if s[i] == ',' {
+			i++
+			continue
+		}
+		if !strings.Contains(closes, string(s[i])) {
+			return "", i, fmt.Errorf("'%v' has invalid token at %v: %v", s, i, s[i])
+		}
+		break
+	}
+	close := s[i]
+
+	if open != '{' {
+		if len(elems) != 2 {
+			return "", i, fmt.Errorf("'%v' expects a range with two bounds at %v", s, i)
+		}
+		lower, _ := strconv.ParseFloat(elems[0], 64)
+		upper, _ := strconv.ParseFloat(elems[1], 64)
+		if lower > upper {
+			return "", i, fmt.Errorf("'%v' has invalid range at %v: %v > %v", s, i, elems[0], elems[1])
+		}
+	}
+	op := fmt.Sprintf("in%c%s%c", open, strings.Join(elems, ","), close)
+	return op, i + 1, nil
 }
 
 var (
@@ -140,194 +320,417 @@ var (
 	errInvalidCond  = errors.New("invalid condition")
 )
 
-// Calculate calculate condition result with float64.
-func (rpn *ReversePolishNotation) Calculate(value float64) (bool, error) {
-	values := list.New()
+type opcode uint8
 
-	for i, n := 0, len(rpn.notation); i < n; i++ {
-		op := rpn.notation[i]
-		switch op {
-		case "+":
-			if values.Len() < 2 {
-				return false, errInvalidExpr
-			}
-			num1, num2, ok := lastTwoNum(values)
-			if !ok {
-				return false, errInvalidExpr
-			}
-			values.PushBack((num1 + num2))
-		case "-":
-			if values.Len() < 2 {
-				return false, errInvalidExpr
-			}
-			num1, num2, ok := lastTwoNum(values)
-			if !ok {
-				return false, errInvalidExpr
-			}
-			values.PushBack((num1 - num2))
-		case "*":
-			if values.Len() < 2 {
-				return false, errInvalidExpr
-			}
-			num1, num2, ok := lastTwoNum(values)
-			if !ok {
-				return false, errInvalidExpr
-			}
-			values.PushBack((num1 * num2))
-		case "/":
-			if values.Len() < 2 {
-				return false, errInvalidExpr
-			}
-			num1, num2, ok := lastTwoNum(values)
-			if !ok {
-				return false, errInvalidExpr
-			}
-			if num2 == 0.0 {
-				return false, errDivideByZero
+const (
+	opNum opcode = iota
+	opN
+	opAdd
+	opSub
+	opMul
+	opDiv
+	opMod
+	opLT
+	opGT
+	opLE
+	opGE
+	opEQ
+	opNE
+	opOr
+	opAnd
+	opNot
+	opTernary
+	opIn
+	// opJumpIfFalse pops the condition and jumps if it is false, opJump
+	// jumps unconditionally, the ternary operator is compiled into them.
+	opJumpIfFalse
+	opJump
+)
+
+var opcodes = map[string]opcode{
+	"N":  opN,
+	"+":  opAdd,
+	"-":  opSub,
+	"*":  opMul,
+	"/":  opDiv,
+	"%":  opMod,
+	"<":  opLT,
+	">":  opGT,
+	"<=": opLE,
+	">=": opGE,
+	"==": opEQ,
+	"!=": opNE,
+	"||": opOr,
+	"&&": opAnd,
+	"!":  opNot,
+	"?:": opTernary,
+}
+
+// instruction is the compiled form of a notation, the numbers and
+// sets are parsed ahead, so that Calculate does no parsing work.
+type instruction struct {
+	op     opcode
+	num    float64
+	member *membership
+	// jump is the index of the target instruction of opJumpIfFalse and opJump.
+	jump int
+}
+
+// membership represents a range or a set, e.g. "[30,80]" or "{1,2,5}".
+type membership struct {
+	negative bool
+	isSet    bool
+	lowerIn  bool
+	upperIn  bool
+	nums     []float64
+}
+
+func (m *membership) contains(num float64) bool {
+	in := false
+	if m.isSet {
+		for _, n := range m.nums {
+			if num == n {
+				in = true
+				break
 			}
-			values.PushBack((num1 / num2))
-		case "%":
-			if values.Len() < 2 {
-				return false, errInvalidExpr
+		}
+	} else {
+		lower, upper := m.nums[0], m.nums[1]
+		in = (num > lower || (m.lowerIn && num == lower)) &&
+			(num < upper || (m.upperIn && num == upper))
+	}
+	return in != m.negative
+}
+
+func compile(notation []string) ([]instruction, error) {
+	program := make([]instruction, 0, len(notation))
+	for _, op := range notation {
+		if code, ok := opcodes[op]; ok {
+			program = append(program, instruction{op: code})
+			continue
+		}
+		if isMembership(op) {
+			m, err := compileMembership(op)
+			if err != nil {
+				return nil, err
 			}
-			num1, num2, ok := lastTwoNum(values)
-			if !ok {
+			program = append(program, instruction{op: opIn, member: m})
+			continue
+		}
+		num, err := strconv.ParseFloat(op, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number: %v", err)
+		}
+		program = append(program, instruction{op: opNum, num: num})
+	}
+	return program, nil
+}
+
+// compileMembership compiles the membership operator op,
+// e.g. "in[30,80]", "in(0,1]" or "!in{1,2,5}".
+func compileMembership(op string) (*membership, error) {
+	m := &membership{}
+	if op[0] == '!' {
+		m.negative = true
+		op = op[1:]
+	}
+	op = op[len("in"):]
+	open, close := op[0], op[len(op)-1]
+	m.isSet = open == '{'
+	m.lowerIn = open == '['
+	m.upperIn = close == ']'
+
+	for _, elem := range strings.Split(op[1:len(op)-1], ",") {
+		n, err := strconv.ParseFloat(elem, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number: %v", err)
+		}
+		m.nums = append(m.nums, n)
+	}
+	return m, nil
+}
+
+// node is an operation with its operands in the expression tree.
+type node struct {
+	ins      instruction
+	operands []*node
+}
+
+// branch compiles the ternary operators in the checked program into jumps,
+// so only the chosen branch is calculated:
+//
+//	cond, opJumpIfFalse(else), then, opJump(end), else
+func branch(program []instruction) []instruction {
+	hasTernary := false
+	for _, ins := range program {
+		hasTernary = hasTernary || ins.op == opTernary
+	}
+	if !hasTernary {
+		return program
+	}
+
+	stack := []*node{}
+	for _, ins := range program {
+		arity := 2
+		switch ins.op {
+		case opNum, opN:
+			arity = 0
+		case opNot, opIn:
+			arity = 1
+		case opTernary:
+			arity = 3
+		}
+		n := &node{ins: ins, operands: append([]*node(nil), stack[len(stack)-arity:]...)}
+		stack = append(stack[:len(stack)-arity], n)
+	}
+
+	branched := make([]instruction, 0, len(program)+1)
+	var emit func(n *node)
+	emit = func(n *node) {
+		if n.ins.op != opTernary {
+			for _, operand := range n.operands {
+				emit(operand)
+			}
+			branched = append(branched, n.ins)
+			return
+		}
+		emit(n.operands[0])
+		jumpIfFalse := len(branched)
+		branched = append(branched, instruction{op: opJumpIfFalse})
+		emit(n.operands[1])
+		jump := len(branched)
+		branched = append(branched, instruction{op: opJump})
+		branched[jumpIfFalse].jump = len(branched)
+		emit(n.operands[2])
+		branched[jump].jump = len(branched)
+	}
+	emit(stack[0])
+	return branched
+}
+
+// check checks the operand types of the program without calculating,
+// returns true if the result is a bool.
+func check(program []instruction) (bool, error) {
+	// true for bool, false for number.
+	kinds := []bool{}
+	for _, ins := range program {
+		n := len(kinds)
+		switch ins.op {
+		case opNum, opN:
+			kinds = append(kinds, false)
+		case opAdd, opSub, opMul, opDiv, opMod:
+			if n < 2 || kinds[n-2] || kinds[n-1] {
 				return false, errInvalidExpr
 			}
-			if num2 == 0.0 {
-				return false, errDivideByZero
-			}
-			values.PushBack(float64(int(num1) % int(num2)))
-		case "<":
-			if values.Len() < 2 {
-				return false, errInvalidCond
-			}
-			num1, num2, ok := lastTwoNum(values)
-			if !ok {
+			kinds = kinds[:n-1]
+		case opLT, opGT, opLE, opGE, opEQ, opNE:
+			if n < 2 || kinds[n-2] || kinds[n-1] {
 				return false, errInvalidCond
 			}
-			values.PushBack((num1 < num2))
-		case ">":
-			if values.Len() < 2 {
+			kinds = append(kinds[:n-2], true)
+		case opOr, opAnd:
+			if n < 2 || !kinds[n-2] || !kinds[n-1] {
 				return false, errInvalidCond
 			}
-			num1, num2, ok := lastTwoNum(values)
-			if !ok {
+			kinds = kinds[:n-1]
+		case opNot:
+			if n < 1 || !kinds[n-1] {
 				return false, errInvalidCond
 			}
-			values.PushBack((num1 > num2))
-		case "<=":
-			if values.Len() < 2 {
+		case opTernary:
+			if n < 3 || !kinds[n-3] || kinds[n-2] != kinds[n-1] {
 				return false, errInvalidCond
 			}
-			num1, num2, ok := lastTwoNum(values)
-			if !ok {
+			kinds = append(kinds[:n-3], kinds[n-1])
+		case opIn:
+			if n < 1 || kinds[n-1] {
 				return false, errInvalidCond
 			}
-			values.PushBack((num1 <= num2))
-		case ">=":
-			if values.Len() < 2 {
-				return false, errInvalidCond
-			}
-			num1, num2, ok := lastTwoNum(values)
-			if !ok {
-				return false, errInvalidCond
-			}
-			values.PushBack((num1 >= num2))
-		case "==": // XXX: may someone compare bool values?
-			if values.Len() < 2 {
-				return false, errInvalidCond
-			}
-			num1, num2, ok := lastTwoNum(values)
-			if !ok {
-				return false, errInvalidCond
+			kinds[n-1] = true
+		}
+	}
+	if len(kinds) != 1 {
+		return false, errInvalidExpr
+	}
+	return kinds[0], nil
+}
+
+// operand is a value on the calculating stack, either a number or a bool.
+type operand struct {
+	num    float64
+	b      bool
+	isBool bool
+}
+
+// IsCondition returns true if the result of the notation is a bool,
+// e.g. "N>1" is a condition but "N+1" is not.
+func (rpn *ReversePolishNotation) IsCondition() bool {
+	return rpn.isCond
+}
+
+// Calculate calculate condition result with float64.
+// It is safe for concurrent use and does no allocation in general.
+func (rpn *ReversePolishNotation) Calculate(value float64) (bool, error) {
+	var buf [16]operand
+	values := buf[:0]
+
+	for pc := 0; pc < len(rpn.program); pc++ {
+		ins := &rpn.program[pc]
+		n := len(values)
+		switch ins.op {
+		case opNum:
+			values = append(values, operand{num: ins.num})
+		case opN:
+			values = append(values, operand{num: value})
+		case opAdd, opSub, opMul, opDiv, opMod:
+			if n < 2 || values[n-2].isBool || values[n-1].isBool {
+				return false, errInvalidExpr
 			}
-			values.PushBack((num1 == num2))
-		case "!=": // XXX: may someone compare bool values?
-			if values.Len() < 2 {
-				return false, errInvalidCond
+			num1, num2 := values[n-2].num, values[n-1].num
+			var res float64
+			switch ins.op {
+			case opAdd:
+				res = num1 + num2
+			case opSub:
+				res = num1 - num2
+			case opMul:
+				res = num1 * num2
+			case opDiv:
+				if num2 == 0.0 {
+					return false, errDivideByZero
+				}
+				res = num1 / num2
+			case opMod:
+				if num2 == 0.0 {
+					return false, errDivideByZero
+				}
+				res = float64(int(num1) % int(num2))
 			}
-			num1, num2, ok := lastTwoNum(values)
-			if !ok {
+			values = append(values[:n-2], operand{num: res})
+		case opLT, opGT, opLE, opGE, opEQ, opNE: // XXX: may someone compare bool values?
+			if n < 2 || values[n-2].isBool || values[n-1].isBool {
 				return false, errInvalidCond
 			}
-			values.PushBack((num1 != num2))
-		case "||":
-			if values.Len() < 2 {
+			num1, num2 := values[n-2].num, values[n-1].num
+			var res bool
+			switch ins.op {
+			case opLT:
+				res = num1 < num2
+			case opGT:
+				res = num1 > num2
+			case opLE:
+				res = num1 <= num2
+			case opGE:
+				res = num1 >= num2
+			case opEQ:
+				res = num1 == num2
+			case opNE:
+				res = num1 != num2
+			}
+			values = append(values[:n-2], operand{b: res, isBool: true})
+		case opOr, opAnd:
+			if n < 2 || !values[n-2].isBool || !values[n-1].isBool {
 				return false, errInvalidCond
 			}
-			b1, b2, ok := lastTwoBool(values)
-			if !ok {
-				return false, errInvalidCond
+			b1, b2 := values[n-2].b, values[n-1].b
+			res := b1 && b2
+			if ins.op == opOr {
+				res = b1 || b2
 			}
-			values.PushBack((b1 || b2))
-		case "&&":
-			if values.Len() < 2 {
+			values = append(values[:n-2], operand{b: res, isBool: true})
+		case opNot:
+			if n < 1 || !values[n-1].isBool {
 				return false, errInvalidCond
 			}
-			b1, b2, ok := lastTwoBool(values)
-			if !ok {
+			values[n-1].b = !values[n-1].b
+		case opJumpIfFalse:
+			if n < 1 || !values[n-1].isBool {
 				return false, errInvalidCond
 			}
-			values.PushBack((b1 && b2))
-		case "!":
-			if values.Len() < 1 {
-				return false, errInvalidCond
+			cond := values[n-1].b
+			values = values[:n-1]
+			if !cond {
+				pc = ins.jump - 1
 			}
-			e := values.Back()
-			b, ok := e.Value.(bool)
-			if !ok {
+		case opJump:
+			pc = ins.jump - 1
+		case opIn:
+			if n < 1 || values[n-1].isBool {
 				return false, errInvalidCond
 			}
-			values.Remove(e)
-			values.PushBack((!b))
-		case "N":
-			values.PushBack(value)
-		default:
-			num, err := strconv.ParseFloat(op, 64)
-			if err != nil {
-				return false, fmt.Errorf("invalid number: %v", err)
-			}
-			values.PushBack(num)
+			values[n-1] = operand{b: ins.member.contains(values[n-1].num), isBool: true}
 		}
 	}
 
-	if values.Len() != 1 {
+	if len(values) != 1 || !values[0].isBool {
 		return false, errInvalidCond
 	}
-	e := values.Back()
-	if res, ok := e.Value.(bool); ok {
-		return res, nil
-	}
-	return false, errInvalidCond
+	return values[0].b, nil
 }
 
-func lastTwoNum(values *list.List) (num1 float64, num2 float64, ok bool) {
-	e2 := values.Back()
-	if num2, ok = e2.Value.(float64); !ok {
-		return
-	}
-	values.Remove(e2)
-
-	e1 := values.Back()
-	if num1, ok = e1.Value.(float64); !ok {
-		return
-	}
-	values.Remove(e1)
-	return
+type expr struct {
+	s        string
+	priority int
 }
 
-func lastTwoBool(values *list.List) (v1 bool, v2 bool, ok bool) {
-	e2 := values.Back()
-	if v2, ok = e2.Value.(bool); !ok {
-		return
+// String returns the normalized infix expression, such as "N >= 30 && N <= 80",
+// the redundant parentheses are removed.
+func (rpn *ReversePolishNotation) String() string {
+	const atom = 5
+	exprs := []expr{}
+	wrap := func(e expr, parens bool) string {
+		if parens {
+			return "(" + e.s + ")"
+		}
+		return e.s
+	}
+	pop := func() expr {
+		e := exprs[len(exprs)-1]
+		exprs = exprs[:len(exprs)-1]
+		return e
 	}
-	values.Remove(e2)
 
-	e1 := values.Back()
-	if v1, ok = e1.Value.(bool); !ok {
-		return
+	for _, op := range rpn.notation {
+		switch {
+		case op == "N" || strings.Contains(numbers, op[:1]):
+			exprs = append(exprs, expr{s: op, priority: atom})
+		case op == "!":
+			if len(exprs) < 1 {
+				return strings.Join(rpn.notation, " ")
+			}
+			exprs = append(exprs, expr{s: "!(" + pop().s + ")", priority: atom})
+		case op == "?:":
+			if len(exprs) < 3 {
+				return strings.Join(rpn.notation, " ")
+			}
+			e2, e1, cond := pop(), pop(), pop()
+			s := wrap(cond, cond.priority == 0) + " ? " + wrap(e1, e1.priority == 0) + " : " + e2.s
+			exprs = append(exprs, expr{s: s, priority: 0})
+		case isMembership(op):
+			if len(exprs) < 1 {
+				return strings.Join(rpn.notation, " ")
+			}
+			e := pop()
+			keyword := "in "
+			if op[0] == '!' {
+				keyword = "not in "
+				op = op[1:]
+			}
+			set := strings.Replace(op[len("in"):], ",", ", ", -1)
+			s := wrap(e, e.priority < 2) + " " + keyword + set
+			exprs = append(exprs, expr{s: s, priority: 2})
+		default:
+			if len(exprs) < 2 {
+				return strings.Join(rpn.notation, " ")
+			}
+			p := int(priority(op))
+			right, left := pop(), pop()
+			s := wrap(left, left.priority < p) + " " + op + " " + wrap(right, right.priority <= p)
+			exprs = append(exprs, expr{s: s, priority: p})
+		}
+	}
+	if len(exprs) != 1 {
+		return strings.Join(rpn.notation, " ")
 	}
-	values.Remove(e1)
-	return
+	return exprs[0].s
 }
@@ -145,6 +145,133 @@ func TestRPN(t *testing.T) {
 	}
 }
 
+func TestMembershipRPN(t *testing.T) {
+	{
+		rpn, err := New("N in [30, 80]")
+		assert.Must(t, err)
+		assertStringList(t, rpn.notation, []string{"N", "in[30,80]"})
+		for n, expect := range map[float64]bool{29: false, 30: true, 80: true, 81: false} {
+			res, err := rpn.Calculate(n)
+			assert.Must(t, err)
+			assert.Check(t, res, expect)
+		}
+	}
+	{
+		rpn, err := New("N in (0, 1]")
+		assert.Must(t, err)
+		assertStringList(t, rpn.notation, []string{"N", "in(0,1]"})
+		for n, expect := range map[float64]bool{0: false, 0.5: true, 1: true} {
+			res, err := rpn.Calculate(n)
+			assert.Must(t, err)
+			assert.Check(t, res, expect)
+		}
+	}
+	{
+		rpn, err := New("N%10 not in {1, 2, 5}&&N>0")
+		assert.Must(t, err)
+		assertStringList(t, rpn.notation, []string{"N", "10", "%", "!in{1,2,5}", "N", "0", ">", "&&"})
+		for n, expect := range map[float64]bool{3: true, 12: false, 15: false, 0: false} {
+			res, err := rpn.Calculate(n)
+			assert.Must(t, err)
+			assert.Check(t, res, expect)
+		}
+	}
+	{
+		rpn, err := New("N in {-1.5, 2}")
+		assert.Must(t, err)
+		res, err := rpn.Calculate(-1.5)
+		assert.Must(t, err)
+		assert.Check(t, res, true)
+	}
+	for _, s := range []string{"N in [80, 30]", "N in [1, 2, 3]", "N in {1, 2", "N in 1", "N on {1}", "N not {1}", "N in {}"} {
+		if _, err := New(s); err == nil {
+			t.Fatalf("expect error for %q, got nothing", s)
+		}
+	}
+}
+
+func TestTernaryRPN(t *testing.T) {
+	{
+		rpn, err := New("N>100?N%10==0:N>0")
+		assert.Must(t, err)
+		assertStringList(t, rpn.notation, []string{"N", "100", ">", "N", "10", "%", "0", "==", "N", "0", ">", "?:"})
+		for n, expect := range map[float64]bool{110: true, 111: false, 11: true, 0: false} {
+			res, err := rpn.Calculate(n)
+			assert.Must(t, err)
+			assert.Check(t, res, expect)
+		}
+	}
+	{
+		rpn, err := New("N<1 ? N>0 : N<10 ? N%2==0 : N in [20, 30]")
+		assert.Must(t, err)
+		for n, expect := range map[float64]bool{0.5: true, 4: true, 5: false, 25: true, 31: false} {
+			res, err := rpn.Calculate(n)
+			assert.Must(t, err)
+			assert.Check(t, res, expect)
+		}
+	}
+	{
+		rpn, err := New("(N>1 ? 2 : 3)*N==6")
+		assert.Must(t, err)
+		res, err := rpn.Calculate(3)
+		assert.Must(t, err)
+		assert.Check(t, res, true)
+		res, err = rpn.Calculate(2)
+		assert.Must(t, err)
+		assert.Check(t, res, false)
+	}
+	{
+		// Only the chosen branch is calculated.
+		rpn, err := New("N==0 ? N==0 : 10/N>1")
+		assert.Must(t, err)
+		for n, expect := range map[float64]bool{0: true, 5: true, 10: false} {
+			res, err := rpn.Calculate(n)
+			assert.Must(t, err)
+			assert.Check(t, res, expect)
+		}
+		rpn, err = New("N!=0 ? (N>0 ? 10%N==0 : 10/N+1<0) : 1/N>0")
+		assert.Must(t, err)
+		res, err := rpn.Calculate(5)
+		assert.Must(t, err)
+		assert.Check(t, res, true)
+		res, err = rpn.Calculate(-20)
+		assert.Must(t, err)
+		assert.Check(t, res, false)
+		_, err = rpn.Calculate(0)
+		assert.Check(t, err, errDivideByZero)
+	}
+	for _, s := range []string{"N>1 ? 2", "N>1 : 2", "(N>1 ? 2) : 3"} {
+		if _, err := New(s); err == nil {
+			t.Fatalf("expect error for %q, got nothing", s)
+		}
+	}
+}
+
+func TestRPNString(t *testing.T) {
+	cases := map[string]string{
+		"N>=30&&N<=80":                 "N >= 30 && N <= 80",
+		"(N%2==0)&&(N<=4||N>=8)":       "N % 2 == 0 && (N <= 4 || N >= 8)",
+		"!((N*2>20)||(N<=8&&N%2==0))":  "!(N * 2 > 20 || (N <= 8 && N % 2 == 0))",
+		"(N-1)-(N-2)>0":                "N - 1 - (N - 2) > 0",
+		"N*(N-3)>=10":                  "N * (N - 3) >= 10",
+		"N in [30,80]":                 "N in [30, 80]",
+		"N+1 not in {1,2,5}":           "N + 1 not in {1, 2, 5}",
+		"N>1?N<3:N>5":                  "N > 1 ? N < 3 : N > 5",
+		"(N>1?N<3:N>5)?N>0:N<0":        "(N > 1 ? N < 3 : N > 5) ? N > 0 : N < 0",
+		"(N>1 ? 2 : 3)*N==6":           "(N > 1 ? 2 : 3) * N == 6",
+		"N>1 ? N<3 : N>5 ? N<7 : N>10": "N > 1 ? N < 3 : N > 5 ? N < 7 : N > 10",
+	}
+	for s, expect := range cases {
+		rpn, err := New(s)
+		assert.Must(t, err)
+		assert.Check(t, rpn.String(), expect)
+		// The normalized expression must be parsed into the same notation.
+		again, err := New(rpn.String())
+		assert.Must(t, err)
+		assertStringList(t, again.notation, rpn.notation)
+	}
+}
+
 func assertStringList(t *testing.T, l1 []string, l2 []string) {
 	if len(l1) != len(l2) {
 		t.Fatalf("%v != %v\n", l1, l2)
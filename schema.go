@@ -0,0 +1,406 @@
+package cc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaError is a violation of the JSON Schema.
+type SchemaError struct {
+	// Path is the JSON pointer of the violated value, e.g. "/map/child/key_three".
+	Path string
+	// Message describes the violation.
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "(root)"
+	}
+	return path + ": " + e.Message
+}
+
+// SchemaErrors is a list of SchemaError, it is returned by ValidateSchema.
+type SchemaErrors []*SchemaError
+
+func (es SchemaErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ValidateSchema validates the Config's internal data against the JSON Schema,
+// excludes the flags and environment variables. It returns SchemaErrors which
+// contains all the violations, or an error if the schema is not valid JSON or
+// the references can not be resolved, see Resolve.
+//
+// The following subset of JSON Schema is supported:
+//
+//	type, enum, const
+//	allOf, anyOf, oneOf, not
+//	minimum, maximum, exclusiveMinimum, exclusiveMaximum, multipleOf
+//	minLength, maxLength, pattern, format
+//	items, minItems, maxItems, uniqueItems
+//	properties, required, additionalProperties, minProperties, maxProperties
+//
+// The "format" is validated by the formats of the "format:" pattern, the
+// unknown formats are ignored.
+func (c *Config) ValidateSchema(schema []byte) error {
+	var s interface{}
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return fmt.Errorf("invalid JSON Schema: %v", err)
+	}
+	kv, err := c.Resolve()
+	if err != nil {
+		return err
+	}
+	v := &schemaValidator{regexps: map[string]*regexp.Regexp{}}
+	v.validate(s, normalize(kv), "")
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// normalize converts the Configer and map[interface{}]interface{} into
+// map[string]interface{} recursively, and the json.Number into int64 if it is
+// an integer in range, otherwise float64.
+func normalize(v interface{}) interface{} {
+	switch x := v.(type) {
+	case json.Number:
+		if n, err := x.Int64(); err == nil {
+			return n
+		}
+		if f, err := x.Float64(); err == nil {
+			return f
+		}
+	case Configer:
+		return normalize(x.KV())
+	case map[interface{}]interface{}:
+		return normalize(unknownMapToStringMap(x))
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			m[k] = normalize(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(x))
+		for i, e := range x {
+			l[i] = normalize(e)
+		}
+		return l
+	}
+	return v
+}
+
+type schemaValidator struct {
+	errs    SchemaErrors
+	regexps map[string]*regexp.Regexp
+}
+
+func (v *schemaValidator) errorf(path string, format string, args ...interface{}) {
+	v.errs = append(v.errs, &SchemaError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// valid reports whether the value is valid without recording the errors.
+func (v *schemaValidator) valid(schema interface{}, value interface{}, path string) bool {
+	sub := &schemaValidator{regexps: v.regexps}
+	sub.validate(schema, value, path)
+	return len(sub.errs) == 0
+}
+
+func (v *schemaValidator) validate(schema interface{}, value interface{}, path string) {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.errorf(path, "value is not allowed")
+		}
+		return
+	case map[string]interface{}:
+		v.validateGeneric(s, value, path)
+		if num, ok := asFloat64(value); ok {
+			v.validateNumber(s, num, path)
+		}
+		switch x := value.(type) {
+		case string:
+			v.validateString(s, x, x, path)
+		case Secret:
+			v.validateString(s, x.Reveal(), x, path)
+		case []interface{}:
+			v.validateArray(s, x, path)
+		case map[string]interface{}:
+			v.validateObject(s, x, path)
+		}
+	default:
+		v.errorf(path, "invalid schema: %v", schema)
+	}
+}
+
+func (v *schemaValidator) validateGeneric(s map[string]interface{}, value interface{}, path string) {
+	switch t := s["type"].(type) {
+	case string:
+		if !isType(value, t) {
+			v.errorf(path, "expected %s, got %s", t, typeOf(value))
+		}
+	case []interface{}:
+		matched := false
+		types := make([]string, len(t))
+		for i, e := range t {
+			types[i] = fmt.Sprintf("%v", e)
+			matched = matched || isType(value, types[i])
+		}
+		if !matched {
+			v.errorf(path, "expected one of %s, got %s", strings.Join(types, ", "), typeOf(value))
+		}
+	}
+	if enum, ok := s["enum"].([]interface{}); ok {
+		matched := false
+		for _, e := range enum {
+			if equal(e, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.errorf(path, "%v is not one of %v", value, enum)
+		}
+	}
+	if c, ok := s["const"]; ok && !equal(c, value) {
+		v.errorf(path, "%v is not %v", value, c)
+	}
+
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			v.validate(sub, value, path)
+		}
+	}
+	if any, ok := s["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range any {
+			if v.valid(sub, value, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.errorf(path, "value does not match any schema of anyOf")
+		}
+	}
+	if one, ok := s["oneOf"].([]interface{}); ok {
+		n := 0
+		for _, sub := range one {
+			if v.valid(sub, value, path) {
+				n++
+			}
+		}
+		if n != 1 {
+			v.errorf(path, "value matches %d schemas of oneOf, expected exactly one", n)
+		}
+	}
+	if not, ok := s["not"]; ok && v.valid(not, value, path) {
+		v.errorf(path, "value must not match the schema of not")
+	}
+}
+
+func (v *schemaValidator) validateNumber(s map[string]interface{}, num float64, path string) {
+	if min, ok := s["minimum"].(float64); ok && num < min {
+		v.errorf(path, "%v is less than the minimum %v", num, min)
+	}
+	if max, ok := s["maximum"].(float64); ok && num > max {
+		v.errorf(path, "%v is greater than the maximum %v", num, max)
+	}
+	if min, ok := s["exclusiveMinimum"].(float64); ok && num <= min {
+		v.errorf(path, "%v is not greater than the exclusive minimum %v", num, min)
+	}
+	if max, ok := s["exclusiveMaximum"].(float64); ok && num >= max {
+		v.errorf(path, "%v is not less than the exclusive maximum %v", num, max)
+	}
+	if m, ok := s["multipleOf"].(float64); ok && m > 0 {
+		if q := num / m; q != math.Trunc(q) {
+			v.errorf(path, "%v is not a multiple of %v", num, m)
+		}
+	}
+}
+
+// validateString validates str, the display is in the errors instead of str,
+// so the Secrets are redacted.
+func (v *schemaValidator) validateString(s map[string]interface{}, str string, display interface{}, path string) {
+	n := utf8.RuneCountInString(str)
+	if min, ok := s["minLength"].(float64); ok && float64(n) < min {
+		v.errorf(path, "length %d is less than the minLength %v", n, min)
+	}
+	if max, ok := s["maxLength"].(float64); ok && float64(n) > max {
+		v.errorf(path, "length %d is greater than the maxLength %v", n, max)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, in := v.regexps[pattern]
+		if !in {
+			var err error
+			if re, err = regexp.Compile(pattern); err != nil {
+				v.errorf(path, "invalid pattern %q: %v", pattern, err)
+			}
+			v.regexps[pattern] = re
+		}
+		if re != nil && !re.MatchString(str) {
+			v.errorf(path, "%q does not match the pattern %q", display, pattern)
+		}
+	}
+	if format, ok := s["format"].(string); ok {
+		if match, known := formats[format]; known && !match(str) {
+			v.errorf(path, "%q is not a valid %s", display, format)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(s map[string]interface{}, list []interface{}, path string) {
+	if min, ok := s["minItems"].(float64); ok && float64(len(list)) < min {
+		v.errorf(path, "%d items is less than the minItems %v", len(list), min)
+	}
+	if max, ok := s["maxItems"].(float64); ok && float64(len(list)) > max {
+		v.errorf(path, "%d items is greater than the maxItems %v", len(list), max)
+	}
+	if unique, _ := s["uniqueItems"].(bool); unique {
+	outer:
+		for i := 1; i < len(list); i++ {
+			for j := 0; j < i; j++ {
+				if equal(list[i], list[j]) {
+					v.errorf(path, "items %d and %d are equal", j, i)
+					break outer
+				}
+			}
+		}
+	}
+	switch items := s["items"].(type) {
+	case []interface{}: // tuple
+		for i, e := range list {
+			if i >= len(items) {
+				break
+			}
+			v.validate(items[i], e, fmt.Sprintf("%s/%d", path, i))
+		}
+	case nil:
+	default:
+		for i, e := range list {
+			v.validate(items, e, fmt.Sprintf("%s/%d", path, i))
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(s map[string]interface{}, obj map[string]interface{}, path string) {
+	if min, ok := s["minProperties"].(float64); ok && float64(len(obj)) < min {
+		v.errorf(path, "%d properties is less than the minProperties %v", len(obj), min)
+	}
+	if max, ok := s["maxProperties"].(float64); ok && float64(len(obj)) > max {
+		v.errorf(path, "%d properties is greater than the maxProperties %v", len(obj), max)
+	}
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, r := range required {
+			name := fmt.Sprintf("%v", r)
+			if _, in := obj[name]; !in {
+				v.errorf(path, "missing required property %q", name)
+			}
+		}
+	}
+
+	props, _ := s["properties"].(map[string]interface{})
+	additional, hasAdditional := s["additionalProperties"]
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		subpath := path + "/" + escapePointer(k)
+		if sub, in := props[k]; in {
+			v.validate(sub, obj[k], subpath)
+		} else if hasAdditional {
+			if allowed, ok := additional.(bool); ok && !allowed {
+				v.errorf(subpath, "additional property is not allowed")
+			} else {
+				v.validate(additional, obj[k], subpath)
+			}
+		}
+	}
+}
+
+// escapePointer escapes the reference token of JSON pointer, see RFC 6901.
+func escapePointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
+
+func isType(v interface{}, t string) bool {
+	switch t {
+	case "integer":
+		num, ok := asFloat64(v)
+		return ok && num == math.Trunc(num)
+	case "number":
+		_, ok := asFloat64(v)
+		return ok
+	}
+	return typeOf(v) == t
+}
+
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string, Secret:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	if num, ok := asFloat64(v); ok {
+		if num == math.Trunc(num) {
+			return "integer"
+		}
+		return "number"
+	}
+	return reflect.TypeOf(v).String()
+}
+
+// equal compares the JSON values, the numbers are compared by value.
+func equal(a, b interface{}) bool {
+	if x, ok := asFloat64(a); ok {
+		y, ok := asFloat64(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, e := range x {
+			if !equal(e, y[k]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
@@ -0,0 +1,103 @@
+package cc
+
+import (
+	"testing"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestConfigValidateSchema(t *testing.T) {
+	c, err := NewConfigFromFile("./example/example.yaml")
+	assert.Must(t, err)
+	c.Config("map") // converts the child into Configer
+
+	schema := []byte(`{
+		"type": "object",
+		"required": ["name", "map", "list"],
+		"properties": {
+			"name": {"type": "string", "enum": ["cc", "dd"]},
+			"map": {
+				"type": "object",
+				"properties": {
+					"key_one": {"type": "boolean"},
+					"child": {
+						"type": "object",
+						"properties": {
+							"key_three": {"type": "integer", "minimum": 1, "maximum": 65535},
+							"key_four": {"type": "string", "minLength": 2, "pattern": "^g"}
+						},
+						"additionalProperties": false
+					}
+				}
+			},
+			"list": {"type": "array", "minItems": 1, "items": {"type": ["string", "number", "boolean"]}},
+			"patterns": {"type": "object", "additionalProperties": {"type": "string"}}
+		}
+	}`)
+	assert.Must(t, c.ValidateSchema(schema))
+
+	c.Config("map").Config("child").Set("key_three", 70000)
+	c.Config("map").Config("child").Set("key~/five", 5)
+	c.Set("name", 1)
+	c.Set("list", []interface{}{map[string]interface{}{}})
+	c.Config("patterns").Set("x", 1)
+	err = c.ValidateSchema(schema)
+	errs, ok := err.(SchemaErrors)
+	if !ok {
+		t.Fatalf("expect SchemaErrors, got %v", err)
+	}
+	expected := []string{
+		"/list/0: expected one of string, number, boolean, got object",
+		"/map/child/key_three: 70000 is greater than the maximum 65535",
+		"/map/child/key~0~1five: additional property is not allowed",
+		"/name: expected string, got integer",
+		"/name: 1 is not one of [cc dd]",
+		"/patterns/x: expected string, got integer",
+	}
+	assert.Check(t, len(errs), len(expected))
+	for i, e := range errs {
+		assert.Check(t, e.Error(), expected[i])
+	}
+
+	if err := c.ValidateSchema([]byte(`{`)); err == nil {
+		t.Fatal("expect error, got nothing")
+	} else if _, ok := err.(SchemaErrors); ok {
+		t.Fatalf("expect invalid schema error, got %v", err)
+	}
+
+	c.Set("ref", "${non.existent}")
+	err = c.ValidateSchema([]byte(`{"properties": {"ref": {"type": "string"}}}`))
+	if _, ok := err.(SchemaErrors); ok || err == nil {
+		t.Fatalf("expect resolve error, got %v", err)
+	}
+	_, resolveErr := c.Resolve()
+	assert.Check(t, err.Error(), resolveErr.Error())
+}
+
+func TestSchemaCombinators(t *testing.T) {
+	schema := []byte(`{
+		"required": ["port", "mode"],
+		"properties": {
+			"port": {"oneOf": [{"type": "integer", "exclusiveMinimum": 0}, {"type": "string", "format": "hostname"}]},
+			"mode": {"anyOf": [{"const": "a"}, {"enum": ["b", "c"]}]},
+			"ratio": {"allOf": [{"minimum": 0}, {"maximum": 1}], "not": {"const": 0.5}, "multipleOf": 0.25},
+			"tags": {"type": "array", "uniqueItems": true, "maxItems": 2}
+		}
+	}`)
+	c := NewConfigFrom(map[string]interface{}{"port": 80, "mode": "b", "ratio": 0.75, "tags": []interface{}{"x", "y"}})
+	assert.Must(t, c.ValidateSchema(schema))
+
+	c = NewConfigFrom(map[string]interface{}{"port": "a_b", "ratio": 0.5, "tags": []interface{}{1, 1.0, 2}})
+	errs := c.ValidateSchema(schema).(SchemaErrors)
+	expected := []string{
+		"(root): missing required property \"mode\"",
+		"/port: value matches 0 schemas of oneOf, expected exactly one",
+		"/ratio: value must not match the schema of not",
+		"/tags: 3 items is greater than the maxItems 2",
+		"/tags: items 0 and 1 are equal",
+	}
+	assert.Check(t, len(errs), len(expected))
+	for i, e := range errs {
+		assert.Check(t, e.Error(), expected[i])
+	}
+}
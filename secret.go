@@ -0,0 +1,194 @@
+package cc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	secretScheme   = "secret://"
+	secretRedacted = "[REDACTED]"
+)
+
+// Secret is a sensitive string, e.g. a password, it is redacted in String,
+// GoString and MarshalText, so it never ends up in the logs or the config
+// dumps by accident, use Reveal to get the value.
+//
+// The secret references are resolved into Secrets, see SecretProvider.
+type Secret string
+
+// Reveal returns the secret value.
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// String implements the fmt.Stringer, returns the redacted value.
+func (s Secret) String() string {
+	return secretRedacted
+}
+
+// GoString implements the fmt.GoStringer, returns the redacted value.
+func (s Secret) GoString() string {
+	return secretRedacted
+}
+
+// MarshalText implements the encoding.TextMarshaler, returns the redacted
+// value, so the JSON and YAML dumps are redacted too.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(secretRedacted), nil
+}
+
+// SecretProvider provides the secrets from a source, the secret reference
+// "secret://<source>/<path>" is resolved by the provider of the source with
+// the path, e.g. "secret://vault/db/password". The sources "file" and "env"
+// are built-in:
+//
+//	secret://file/run/secrets/db_password  the contents of the file(trailing newlines trimmed)
+//	secret://env/DB_PASS                   the environment variable
+type SecretProvider interface {
+	Secret(path string) (string, error)
+}
+
+// SecretProviderFunc is an adapter to allow the use of ordinary functions
+// as SecretProviders.
+type SecretProviderFunc func(path string) (string, error)
+
+// Secret calls f(path).
+func (f SecretProviderFunc) Secret(path string) (string, error) {
+	return f(path)
+}
+
+// SetSecretProvider sets the provider of the secret references of source,
+// the built-in "file" and "env" providers can be replaced.
+func (c *Config) SetSecretProvider(source string, provider SecretProvider) {
+	if c.secretProviders == nil {
+		c.secretProviders = map[string]SecretProvider{}
+	}
+	c.secretProviders[source] = provider
+}
+
+// resolveSecret resolves the secret reference with the providers of the
+// root Config.
+func (c *Config) resolveSecret(ref string) (Secret, error) {
+	source := strings.TrimPrefix(ref, secretScheme)
+	idx := strings.IndexByte(source, '/')
+	if idx <= 0 {
+		return "", fmt.Errorf("invalid secret reference %q, expected secret://<source>/<path>", ref)
+	}
+	source, path := source[:idx], source[idx+1:]
+
+	root := c.rootConfig()
+	provider, ok := root.secretProviders[source]
+	if !ok {
+		switch source {
+		case "file":
+			provider = SecretProviderFunc(func(path string) (string, error) {
+				data, err := ioutil.ReadFile("/" + path)
+				return strings.TrimRight(string(data), "\r\n"), err
+			})
+		case "env":
+			provider = SecretProviderFunc(func(path string) (string, error) {
+				if v := root.lookupEnv(path); v != "" {
+					return v, nil
+				}
+				return "", fmt.Errorf("environment variable %s is not set", path)
+			})
+		default:
+			return "", fmt.Errorf("unknown secret source %q in %q", source, ref)
+		}
+	}
+	s, err := provider.Secret(path)
+	if err != nil {
+		return "", fmt.Errorf("secret %q: %v", ref, err)
+	}
+	return Secret(s), nil
+}
+
+// Secret returns the Secret value by name, returns "" if not found.
+func (c *Config) Secret(name string) Secret {
+	return c.SecretOr(name, "")
+}
+
+// SecretOr returns the Secret value by name, returns the deflt if not found
+// or can not be resolved.
+func (c *Config) SecretOr(name string, deflt Secret) Secret {
+	if s, err := c.SecretE(name); err == nil {
+		return s
+	}
+	return deflt
+}
+
+// SecretE returns the Secret value by name, returns an error if not found,
+// not a string or the secret reference can not be resolved. The plain
+// strings are Secrets too, the secret references in the flags and
+// environment variables are resolved as well.
+func (c *Config) SecretE(name string) (Secret, error) {
+	var v interface{}
+	if s, ok := c.flag(name).(string); ok && s != "" {
+		v = s
+	} else if env := c.getenv(name); env != "" {
+		v = env
+	} else {
+		found, ok, err := c.lookupE(name)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", name, err)
+		}
+		if !ok || found == nil {
+			return "", fmt.Errorf("%s: not found", name)
+		}
+		v = found
+	}
+	if s, ok := v.(string); ok && strings.HasPrefix(s, secretScheme) {
+		secret, err := c.resolveSecret(s)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", name, err)
+		}
+		return secret, nil
+	}
+	secret, err := toSecretE(v)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", name, err)
+	}
+	return secret, nil
+}
+
+// Secret returns the Secret value, returns "" if not exists.
+func (v *Value) Secret() Secret {
+	return v.SecretOr("")
+}
+
+// SecretOr returns the Secret value, returns the deflt if not exists, not a
+// string or the secret reference can not be resolved.
+func (v *Value) SecretOr(deflt Secret) Secret {
+	if s, err := v.SecretE(); err == nil {
+		return s
+	}
+	return deflt
+}
+
+// SecretE returns the Secret value, returns an error if not exists, not a
+// string or the secret reference can not be resolved, the plain strings are
+// Secrets too. The values from a Config are resolved by its SecretProviders,
+// the others, e.g. NewValue("secret://env/DB_PASS"), by the built-in ones.
+func (v *Value) SecretE() (Secret, error) {
+	if !v.Exist() {
+		return "", errNotExist
+	}
+	return toSecretE(v.v)
+}
+
+// toSecretE converts v into Secret, the secret references are resolved by
+// the built-in SecretProviders.
+func toSecretE(v interface{}) (Secret, error) {
+	switch x := v.(type) {
+	case Secret:
+		return x, nil
+	case string:
+		if strings.HasPrefix(x, secretScheme) {
+			return newConfig().resolveSecret(x)
+		}
+		return Secret(x), nil
+	}
+	return "", fmt.Errorf("expected secret, got %T", v)
+}
@@ -0,0 +1,153 @@
+package cc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/damnever/cc/assert"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestSecretRedacted(t *testing.T) {
+	s := Secret("hunter2")
+	assert.Check(t, s.Reveal(), "hunter2")
+	assert.Check(t, fmt.Sprintf("%v %s %#v", s, s, s), "[REDACTED] [REDACTED] [REDACTED]")
+	data, err := json.Marshal(map[string]interface{}{"password": s})
+	assert.Must(t, err)
+	assert.Check(t, string(data), `{"password":"[REDACTED]"}`)
+	data, err = yaml.Marshal(map[string]interface{}{"password": s})
+	assert.Must(t, err)
+	assert.Check(t, string(data), "password: '[REDACTED]'\n")
+}
+
+func TestSecretReferences(t *testing.T) {
+	f, err := ioutil.TempFile("", "cc-secret")
+	assert.Must(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("from-file\n")
+	assert.Must(t, err)
+	assert.Must(t, f.Close())
+	os.Setenv("CC_TEST_DB_PASS", "from-env")
+	defer os.Unsetenv("CC_TEST_DB_PASS")
+
+	c, err := NewConfigFromYAML([]byte(fmt.Sprintf(`
+db:
+  password: secret://env/CC_TEST_DB_PASS
+  dsn: postgres://app:${db.password}@db/app
+plain: hunter2
+file: secret://file%s
+token: secret://vault/app/token
+missing: secret://env/CC_TEST_MISSING
+invalid: secret://file
+num: 1
+`, f.Name())))
+	assert.Must(t, err)
+	c.SetSecretProvider("vault", SecretProviderFunc(func(path string) (string, error) {
+		if path == "app/token" {
+			return "from-vault", nil
+		}
+		return "", errors.New("not found")
+	}))
+
+	db := c.Config("db")
+	assert.Check(t, db.Secret("password").Reveal(), "from-env")
+	assert.Check(t, db.Secret("dsn").Reveal(), "postgres://app:from-env@db/app")
+	assert.Check(t, db.String("password"), "from-env")
+	assert.Check(t, db.String("dsn"), "postgres://app:from-env@db/app")
+	assert.Check(t, db.Value("password").String(), "")
+	assert.Check(t, c.StringOr("missing", "x"), "x")
+	assert.Check(t, c.Secret("file").Reveal(), "from-file")
+	assert.Check(t, c.Secret("token").Reveal(), "from-vault")
+	assert.Check(t, c.Secret("plain").Reveal(), "hunter2")
+	assert.Check(t, c.SecretOr("non", "x").Reveal(), "x")
+	_, err = c.SecretE("missing")
+	assert.Check(t, err.Error(), `missing: secret "secret://env/CC_TEST_MISSING": environment variable CC_TEST_MISSING is not set`)
+	_, err = c.SecretE("invalid")
+	assert.Check(t, err.Error(), `invalid: invalid secret reference "secret://file", expected secret://<source>/<path>`)
+	_, err = c.SecretE("num")
+	assert.Check(t, err.Error(), "num: expected secret, got int")
+	c.Set("unknown", "secret://aws/x")
+	_, err = c.SecretE("unknown")
+	assert.Check(t, err.Error(), `unknown: unknown secret source "aws" in "secret://aws/x"`)
+
+	// The dumps are redacted.
+	dump := fmt.Sprintf("%#v %v %s", c.Value("db"), c.Value("db"), c.Value("token"))
+	assert.Check(t, strings.Contains(dump, "from-env") || strings.Contains(dump, "from-vault"), false)
+	assert.Check(t, fmt.Sprintf("%#v", c.Value("file")), "[REDACTED]")
+	kv, resolveErr := c.Resolve()
+	assert.Check(t, resolveErr != nil, true)
+	data, err := json.Marshal(kv)
+	assert.Must(t, err)
+	assert.Check(t, strings.Contains(string(data), "from-"), false)
+	assert.Check(t, strings.Contains(string(data), `"password":"[REDACTED]"`), true)
+	assert.Check(t, c.ValidateSchema([]byte(`{"properties": {"token": {"type": "string"}}}`)).Error(), resolveErr.Error())
+
+	os.Setenv("CC_TEST_TOKEN", "secret://env/CC_TEST_DB_PASS")
+	defer os.Unsetenv("CC_TEST_TOKEN")
+	c.BindEnv("token", "CC_TEST_TOKEN")
+	assert.Check(t, c.Secret("token").Reveal(), "from-env")
+
+	var s struct {
+		Token Secret `cc:"token"`
+		DB    struct {
+			Password Secret `cc:"password"`
+		} `cc:"db"`
+		Plain Secret `cc:"plain"`
+	}
+	assert.Must(t, c.Decode(&s))
+	assert.Check(t, s.Token.Reveal(), "from-env")
+	assert.Check(t, s.DB.Password.Reveal(), "from-env")
+	assert.Check(t, s.Plain.Reveal(), "hunter2")
+	var dsn struct {
+		DSN string `cc:"dsn"`
+	}
+	assert.Check(t, db.(*Config).Decode(&dsn).Error(), "dsn: expected string, got cc.Secret")
+}
+
+func TestValueSecret(t *testing.T) {
+	assert.Check(t, NewValue("x").Secret().Reveal(), "x")
+	assert.Check(t, NewValue(Secret("y")).Secret().Reveal(), "y")
+	assert.Check(t, NewValue(1).SecretOr("z").Reveal(), "z")
+	_, err := NewValue(nil).SecretE()
+	assert.Check(t, err, errNotExist)
+
+	os.Setenv("CC_TEST_VALUE_PASS", "from-env")
+	defer os.Unsetenv("CC_TEST_VALUE_PASS")
+	assert.Check(t, NewValue("secret://env/CC_TEST_VALUE_PASS").Secret().Reveal(), "from-env")
+	list := NewValue([]interface{}{"secret://env/CC_TEST_VALUE_PASS"}).List()
+	assert.Check(t, list[0].Secret().Reveal(), "from-env")
+	m := NewValue(map[string]interface{}{"pass": "secret://env/CC_TEST_VALUE_PASS"}).Map()
+	assert.Check(t, m["pass"].Secret().Reveal(), "from-env")
+	assert.Check(t, NewValue("secret://env/CC_TEST_MISSING").SecretOr("x").Reveal(), "x")
+	_, err = NewValue("secret://vault/x").SecretE()
+	assert.Check(t, err.Error(), `unknown secret source "vault" in "secret://vault/x"`)
+
+	c := NewConfigFrom(map[string]interface{}{"passes": []interface{}{"secret://vault/x"}})
+	c.SetSecretProvider("vault", SecretProviderFunc(func(path string) (string, error) {
+		return "from-vault", nil
+	}))
+	assert.Check(t, c.Value("passes").List()[0].Secret().Reveal(), "from-vault")
+
+	var secret Secret
+	assert.Must(t, NewValue("secret://env/CC_TEST_VALUE_PASS").As(&secret))
+	assert.Check(t, secret.Reveal(), "from-env")
+	assert.Check(t, NewValue("secret://env/CC_TEST_MISSING").As(&secret).Error(),
+		`secret "secret://env/CC_TEST_MISSING": environment variable CC_TEST_MISSING is not set`)
+}
+
+func TestSecretSchema(t *testing.T) {
+	os.Setenv("CC_TEST_SCHEMA_PASS", "short")
+	defer os.Unsetenv("CC_TEST_SCHEMA_PASS")
+	c := NewConfigFrom(map[string]interface{}{"password": "secret://env/CC_TEST_SCHEMA_PASS"})
+
+	errs := c.ValidateSchema([]byte(`{"properties": {"password": {"type": "string", "minLength": 8, "pattern": "^[0-9]+$", "format": "email"}}}`)).(SchemaErrors)
+	assert.Check(t, errs.Error(), `/password: length 5 is less than the minLength 8; `+
+		`/password: "[REDACTED]" does not match the pattern "^[0-9]+$"; `+
+		`/password: "[REDACTED]" is not a valid email`)
+	assert.Must(t, c.ValidateSchema([]byte(`{"properties": {"password": {"type": "string", "maxLength": 5, "pattern": "^s"}}}`)))
+}
@@ -0,0 +1,469 @@
+package cc
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// StringSliceFlag is a flag.Value which collects the repeated flags and the
+// comma-separated values, e.g. "-tag a -tag b,c" is ["a", "b", "c"], the
+// slice getters read it after ParseFlags:
+//
+//	flag.Var(&cc.StringSliceFlag{}, "tag", "usage")
+type StringSliceFlag []string
+
+// String implements the flag.Value interface.
+func (f *StringSliceFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return strings.Join(*f, ",")
+}
+
+// Set implements the flag.Value interface.
+func (f *StringSliceFlag) Set(s string) error {
+	*f = append(*f, strings.Split(s, ",")...)
+	return nil
+}
+
+// Get implements the flag.Getter interface.
+func (f *StringSliceFlag) Get() interface{} {
+	return []string(*f)
+}
+
+// sliceValue returns the Value by name for the slice getters, the non-empty
+// StringSliceFlag and the environment variable have priority, the elements
+// of them are coerced leniently, e.g. PORTS="80,443" or "80 443".
+func (c *Config) sliceValue(name string) *Value {
+	if v, ok := c.flag(name).([]string); ok && len(v) > 0 {
+		return &Value{v: v, coercion: CoerceLenient}
+	}
+	if env := c.getenv(name); env != "" {
+		return &Value{v: env, coercion: CoerceLenient}
+	}
+	v, ok := c.lookup(name)
+	if !ok {
+		return NewValue(nil)
+	}
+	return &Value{v: v, coercion: c.coercionOf(name)}
+}
+
+// StringSlice returns the []string value by name, returns nil if not found
+// or any element is not a string.
+func (c *Config) StringSlice(name string) []string {
+	return c.sliceValue(name).StringSlice()
+}
+
+// StringSliceOr returns the []string value by name, returns the deflt if not
+// found or any element is not a string.
+func (c *Config) StringSliceOr(name string, deflt []string) []string {
+	return c.sliceValue(name).StringSliceOr(deflt)
+}
+
+// StringSliceAnd returns the ([]string value, true) by name if all the
+// elements matched the pattern, otherwise returns (nil, false).
+func (c *Config) StringSliceAnd(name string, pattern string) ([]string, bool) {
+	return c.sliceValue(name).StringSliceAnd(pattern)
+}
+
+// StringSliceAndOr returns the []string value by name if all the elements
+// matched the pattern, otherwise returns the deflt.
+func (c *Config) StringSliceAndOr(name string, pattern string, deflt []string) []string {
+	return c.sliceValue(name).StringSliceAndOr(pattern, deflt)
+}
+
+// IntSlice returns the []int value by name, returns nil if not found
+// or any element is not an int.
+func (c *Config) IntSlice(name string) []int {
+	return c.sliceValue(name).IntSlice()
+}
+
+// IntSliceOr returns the []int value by name, returns the deflt if not
+// found or any element is not an int.
+func (c *Config) IntSliceOr(name string, deflt []int) []int {
+	return c.sliceValue(name).IntSliceOr(deflt)
+}
+
+// IntSliceAnd returns the ([]int value, true) by name if all the elements
+// matched the pattern, otherwise returns (nil, false).
+func (c *Config) IntSliceAnd(name string, pattern string) ([]int, bool) {
+	return c.sliceValue(name).IntSliceAnd(pattern)
+}
+
+// IntSliceAndOr returns the []int value by name if all the elements matched
+// the pattern, otherwise returns the deflt.
+func (c *Config) IntSliceAndOr(name string, pattern string, deflt []int) []int {
+	return c.sliceValue(name).IntSliceAndOr(pattern, deflt)
+}
+
+// FloatSlice returns the []float64 value by name, returns nil if not found
+// or any element is not a number.
+func (c *Config) FloatSlice(name string) []float64 {
+	return c.sliceValue(name).FloatSlice()
+}
+
+// FloatSliceOr returns the []float64 value by name, returns the deflt if not
+// found or any element is not a number.
+func (c *Config) FloatSliceOr(name string, deflt []float64) []float64 {
+	return c.sliceValue(name).FloatSliceOr(deflt)
+}
+
+// FloatSliceAnd returns the ([]float64 value, true) by name if all the
+// elements matched the pattern, otherwise returns (nil, false).
+func (c *Config) FloatSliceAnd(name string, pattern string) ([]float64, bool) {
+	return c.sliceValue(name).FloatSliceAnd(pattern)
+}
+
+// FloatSliceAndOr returns the []float64 value by name if all the elements
+// matched the pattern, otherwise returns the deflt.
+func (c *Config) FloatSliceAndOr(name string, pattern string, deflt []float64) []float64 {
+	return c.sliceValue(name).FloatSliceAndOr(pattern, deflt)
+}
+
+// DurationSlice returns the []time.Duration value by name, returns nil if
+// not found or any element is not a duration.
+func (c *Config) DurationSlice(name string) []time.Duration {
+	return c.sliceValue(name).DurationSlice()
+}
+
+// DurationSliceOr returns the []time.Duration value by name, returns the
+// deflt if not found or any element is not a duration.
+func (c *Config) DurationSliceOr(name string, deflt []time.Duration) []time.Duration {
+	return c.sliceValue(name).DurationSliceOr(deflt)
+}
+
+// DurationSliceAnd returns the ([]time.Duration value, true) by name if all
+// the elements matched the pattern, otherwise returns (nil, false).
+// NOTE: we convert all numbers into float64 then validate.
+func (c *Config) DurationSliceAnd(name string, pattern string) ([]time.Duration, bool) {
+	return c.sliceValue(name).DurationSliceAnd(pattern)
+}
+
+// DurationSliceAndOr returns the []time.Duration value by name if all the
+// elements matched the pattern, otherwise returns the deflt.
+// NOTE: we convert all numbers into float64 then validate.
+func (c *Config) DurationSliceAndOr(name string, pattern string, deflt []time.Duration) []time.Duration {
+	return c.sliceValue(name).DurationSliceAndOr(pattern, deflt)
+}
+
+// StringMap returns the map value by name, returns nil if not found or not
+// a map. Excludes the flags and environment variable.
+func (c *Config) StringMap(name string) map[string]interface{} {
+	return c.Value(name).StringMap()
+}
+
+// StringMapOr returns the map value by name, returns the deflt if not found
+// or not a map. Excludes the flags and environment variable.
+func (c *Config) StringMapOr(name string, deflt map[string]interface{}) map[string]interface{} {
+	return c.Value(name).StringMapOr(deflt)
+}
+
+// StringMapString returns the map[string]string value by name, returns nil
+// if not found or any value is not a string.
+// Excludes the flags and environment variable.
+func (c *Config) StringMapString(name string) map[string]string {
+	return c.Value(name).StringMapString()
+}
+
+// StringMapStringOr returns the map[string]string value by name, returns the
+// deflt if not found or any value is not a string.
+// Excludes the flags and environment variable.
+func (c *Config) StringMapStringOr(name string, deflt map[string]string) map[string]string {
+	return c.Value(name).StringMapStringOr(deflt)
+}
+
+// list returns the value as a list, the string is split by commas or spaces
+// if lenient.
+func (v *Value) list() ([]interface{}, bool) {
+	switch x := v.v.(type) {
+	case []interface{}:
+		return x, true
+	case []string:
+		list := make([]interface{}, len(x))
+		for i, e := range x {
+			list[i] = e
+		}
+		return list, true
+	case string:
+		if v.coercion == CoerceLenient {
+			fields := strings.FieldsFunc(x, func(r rune) bool {
+				return r == ',' || unicode.IsSpace(r)
+			})
+			list := make([]interface{}, len(fields))
+			for i, e := range fields {
+				list[i] = e
+			}
+			return list, true
+		}
+	}
+	return nil, false
+}
+
+func (v *Value) stringSlice() ([]string, bool) {
+	list, ok := v.list()
+	if !ok {
+		return nil, false
+	}
+	s := make([]string, len(list))
+	for i, e := range list {
+		if s[i], ok = v.coercion.text(e).(string); !ok {
+			return nil, false
+		}
+	}
+	return s, true
+}
+
+// StringSlice returns the []string value, returns nil if not exists or any
+// element is not a string.
+func (v *Value) StringSlice() []string {
+	return v.StringSliceOr(nil)
+}
+
+// StringSliceOr returns the []string value, returns the deflt if not exists
+// or any element is not a string.
+func (v *Value) StringSliceOr(deflt []string) []string {
+	if s, ok := v.stringSlice(); ok {
+		return s
+	}
+	return deflt
+}
+
+// StringSliceAnd returns the ([]string value, true) if all the elements
+// matched the pattern, otherwise returns (nil, false).
+func (v *Value) StringSliceAnd(pattern string) ([]string, bool) {
+	s, ok := v.stringSlice()
+	if !ok {
+		return nil, false
+	}
+	p := NewPattern(pattern)
+	for _, e := range s {
+		if !p.ValidateString(e) {
+			return nil, false
+		}
+	}
+	return s, true
+}
+
+// StringSliceAndOr returns the []string value if all the elements matched
+// the pattern, otherwise returns the deflt.
+func (v *Value) StringSliceAndOr(pattern string, deflt []string) []string {
+	if s, ok := v.StringSliceAnd(pattern); ok {
+		return s
+	}
+	return deflt
+}
+
+func (v *Value) intSlice() ([]int, bool) {
+	list, ok := v.list()
+	if !ok {
+		return nil, false
+	}
+	s := make([]int, len(list))
+	for i, e := range list {
+		n, ok := asInt64(v.coercion.numeric(e))
+		if !ok || int64(int(n)) != n {
+			return nil, false
+		}
+		s[i] = int(n)
+	}
+	return s, true
+}
+
+// IntSlice returns the []int value, returns nil if not exists or any
+// element is not an int.
+func (v *Value) IntSlice() []int {
+	return v.IntSliceOr(nil)
+}
+
+// IntSliceOr returns the []int value, returns the deflt if not exists or
+// any element is not an int.
+func (v *Value) IntSliceOr(deflt []int) []int {
+	if s, ok := v.intSlice(); ok {
+		return s
+	}
+	return deflt
+}
+
+// IntSliceAnd returns the ([]int value, true) if all the elements matched
+// the pattern, otherwise returns (nil, false).
+func (v *Value) IntSliceAnd(pattern string) ([]int, bool) {
+	s, ok := v.intSlice()
+	if !ok {
+		return nil, false
+	}
+	p := NewPattern(pattern)
+	for _, n := range s {
+		if !p.ValidateInt(n) {
+			return nil, false
+		}
+	}
+	return s, true
+}
+
+// IntSliceAndOr returns the []int value if all the elements matched the
+// pattern, otherwise returns the deflt.
+func (v *Value) IntSliceAndOr(pattern string, deflt []int) []int {
+	if s, ok := v.IntSliceAnd(pattern); ok {
+		return s
+	}
+	return deflt
+}
+
+func (v *Value) floatSlice() ([]float64, bool) {
+	list, ok := v.list()
+	if !ok {
+		return nil, false
+	}
+	s := make([]float64, len(list))
+	for i, e := range list {
+		if s[i], ok = asFloat64(v.coercion.numeric(e)); !ok {
+			return nil, false
+		}
+	}
+	return s, true
+}
+
+// FloatSlice returns the []float64 value, returns nil if not exists or any
+// element is not a number.
+func (v *Value) FloatSlice() []float64 {
+	return v.FloatSliceOr(nil)
+}
+
+// FloatSliceOr returns the []float64 value, returns the deflt if not exists
+// or any element is not a number.
+func (v *Value) FloatSliceOr(deflt []float64) []float64 {
+	if s, ok := v.floatSlice(); ok {
+		return s
+	}
+	return deflt
+}
+
+// FloatSliceAnd returns the ([]float64 value, true) if all the elements
+// matched the pattern, otherwise returns (nil, false).
+func (v *Value) FloatSliceAnd(pattern string) ([]float64, bool) {
+	s, ok := v.floatSlice()
+	if !ok {
+		return nil, false
+	}
+	p := NewPattern(pattern)
+	for _, n := range s {
+		if !p.ValidateFloat(n) {
+			return nil, false
+		}
+	}
+	return s, true
+}
+
+// FloatSliceAndOr returns the []float64 value if all the elements matched
+// the pattern, otherwise returns the deflt.
+func (v *Value) FloatSliceAndOr(pattern string, deflt []float64) []float64 {
+	if s, ok := v.FloatSliceAnd(pattern); ok {
+		return s
+	}
+	return deflt
+}
+
+func (v *Value) durationSlice() ([]time.Duration, bool) {
+	list, ok := v.list()
+	if !ok {
+		return nil, false
+	}
+	s := make([]time.Duration, len(list))
+	for i, e := range list {
+		if d, ok := v.coercion.duration(e); ok {
+			s[i] = d
+			continue
+		}
+		n, ok := asInt64(v.coercion.numeric(e))
+		if !ok {
+			return nil, false
+		}
+		s[i] = time.Duration(n)
+	}
+	return s, true
+}
+
+// DurationSlice returns the []time.Duration value, returns nil if not
+// exists or any element is not a duration.
+func (v *Value) DurationSlice() []time.Duration {
+	return v.DurationSliceOr(nil)
+}
+
+// DurationSliceOr returns the []time.Duration value, returns the deflt if
+// not exists or any element is not a duration.
+func (v *Value) DurationSliceOr(deflt []time.Duration) []time.Duration {
+	if s, ok := v.durationSlice(); ok {
+		return s
+	}
+	return deflt
+}
+
+// DurationSliceAnd returns the ([]time.Duration value, true) if all the
+// elements matched the pattern, otherwise returns (nil, false).
+// NOTE: we convert all numbers into float64 then validate.
+func (v *Value) DurationSliceAnd(pattern string) ([]time.Duration, bool) {
+	s, ok := v.durationSlice()
+	if !ok {
+		return nil, false
+	}
+	p := NewPattern(pattern)
+	for _, d := range s {
+		if !p.ValidateFloat(float64(d)) {
+			return nil, false
+		}
+	}
+	return s, true
+}
+
+// DurationSliceAndOr returns the []time.Duration value if all the elements
+// matched the pattern, otherwise returns the deflt.
+// NOTE: we convert all numbers into float64 then validate.
+func (v *Value) DurationSliceAndOr(pattern string, deflt []time.Duration) []time.Duration {
+	if s, ok := v.DurationSliceAnd(pattern); ok {
+		return s
+	}
+	return deflt
+}
+
+// StringMap returns the value as a map, returns nil if not exists or not a
+// map, the modification on returned map has no affect to the origin value.
+func (v *Value) StringMap() map[string]interface{} {
+	return v.StringMapOr(nil)
+}
+
+// StringMapOr returns the value as a map, returns the deflt if not exists
+// or not a map.
+func (v *Value) StringMapOr(deflt map[string]interface{}) map[string]interface{} {
+	if !isMap(v.v) {
+		return deflt
+	}
+	m := map[string]interface{}{}
+	for k, e := range v.Map() {
+		m[k] = e.Raw()
+	}
+	return m
+}
+
+// StringMapString returns the value as a map[string]string, returns nil if
+// not exists or any value is not a string.
+func (v *Value) StringMapString() map[string]string {
+	return v.StringMapStringOr(nil)
+}
+
+// StringMapStringOr returns the value as a map[string]string, returns the
+// deflt if not exists or any value is not a string.
+func (v *Value) StringMapStringOr(deflt map[string]string) map[string]string {
+	if !isMap(v.v) {
+		return deflt
+	}
+	m := map[string]string{}
+	for k, e := range v.Map() {
+		s, ok := v.coercion.text(e.Raw()).(string)
+		if !ok {
+			return deflt
+		}
+		m[k] = s
+	}
+	return m
+}
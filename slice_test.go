@@ -0,0 +1,97 @@
+package cc
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestSliceGetters(t *testing.T) {
+	c, err := NewConfigFromYAML([]byte(`
+names: [a, b]
+ports: [80, 443]
+ratios: [0.5, 1]
+timeouts: [1000, 2000]
+mixed: [1, x]
+csv: "80,443"
+labels:
+  app: web
+  tier: 1
+nested:
+  child: {k: v}
+`))
+	assert.Must(t, err)
+
+	assert.Check(t, fmt.Sprint(c.StringSlice("names")), fmt.Sprint([]string{"a", "b"}))
+	assert.Check(t, fmt.Sprint(c.IntSlice("ports")), fmt.Sprint([]int{80, 443}))
+	assert.Check(t, fmt.Sprint(c.FloatSlice("ratios")), fmt.Sprint([]float64{0.5, 1}))
+	assert.Check(t, fmt.Sprint(c.DurationSlice("timeouts")), fmt.Sprint([]time.Duration{1000, 2000}))
+	assert.Check(t, fmt.Sprint(c.IntSliceOr("mixed", []int{1})), fmt.Sprint([]int{1}))
+	assert.Check(t, c.StringSliceOr("ports", nil) == nil, true)
+	assert.Check(t, c.IntSlice("non") == nil, true)
+	assert.Check(t, fmt.Sprint(c.IntSliceOr("csv", []int{8080})), fmt.Sprint([]int{8080}))
+
+	ports, ok := c.IntSliceAnd("ports", "N>0&&N<65536")
+	assert.Check(t, ok, true)
+	assert.Check(t, fmt.Sprint(ports), fmt.Sprint([]int{80, 443}))
+	_, ok = c.IntSliceAnd("ports", "N>100")
+	assert.Check(t, ok, false)
+	assert.Check(t, fmt.Sprint(c.IntSliceAndOr("ports", "N>100", []int{443})), fmt.Sprint([]int{443}))
+	assert.Check(t, fmt.Sprint(c.StringSliceAndOr("names", "enum:a|b", nil)), fmt.Sprint([]string{"a", "b"}))
+	assert.Check(t, fmt.Sprint(c.StringSliceAndOr("names", "enum:a", []string{"x"})), fmt.Sprint([]string{"x"}))
+	assert.Check(t, fmt.Sprint(c.FloatSliceAndOr("ratios", "N<=1", nil)), fmt.Sprint([]float64{0.5, 1}))
+	assert.Check(t, c.FloatSliceAndOr("ratios", "N<1", nil) == nil, true)
+	assert.Check(t, fmt.Sprint(c.DurationSliceAndOr("timeouts", "N>=1000", nil)), fmt.Sprint([]time.Duration{1000, 2000}))
+	_, ok = c.DurationSliceAnd("timeouts", "N>1000")
+	assert.Check(t, ok, false)
+
+	assert.Check(t, c.StringMapString("labels") == nil, true)
+	assert.Check(t, fmt.Sprint(c.StringMap("labels")), fmt.Sprint(map[string]interface{}{"app": "web", "tier": 1}))
+	assert.Check(t, c.StringMapOr("ports", nil) == nil, true)
+	assert.Check(t, c.Config("nested").Config("child").String("k"), "v")
+	assert.Check(t, len(c.StringMap("nested")), 1)
+	assert.Check(t, c.Value("labels").StringMapStringOr(nil) == nil, true)
+
+	c.SetCoercion(CoerceLenient)
+	assert.Check(t, fmt.Sprint(c.IntSlice("csv")), fmt.Sprint([]int{80, 443}))
+	assert.Check(t, fmt.Sprint(c.StringSlice("ports")), fmt.Sprint([]string{"80", "443"}))
+	assert.Check(t, fmt.Sprint(c.StringMapString("labels")), fmt.Sprint(map[string]string{"app": "web", "tier": "1"}))
+	assert.Check(t, c.Value("labels").Map()["tier"].String(), "1")
+}
+
+func TestSliceGettersFromEnvAndFlags(t *testing.T) {
+	c := NewConfigFrom(map[string]interface{}{"ports": []interface{}{1}})
+	os.Setenv("CC_TEST_PORTS", "80, 443 8080")
+	os.Setenv("CC_TEST_TIMEOUTS", "1s,500ms")
+	defer os.Unsetenv("CC_TEST_PORTS")
+	defer os.Unsetenv("CC_TEST_TIMEOUTS")
+	c.BindEnv("ports", "CC_TEST_PORTS")
+	c.BindEnv("timeouts", "CC_TEST_TIMEOUTS")
+	assert.Check(t, fmt.Sprint(c.IntSlice("ports")), fmt.Sprint([]int{80, 443, 8080}))
+	assert.Check(t, fmt.Sprint(c.StringSlice("ports")), fmt.Sprint([]string{"80", "443", "8080"}))
+	assert.Check(t, fmt.Sprint(c.DurationSlice("timeouts")), fmt.Sprint([]time.Duration{time.Second, 500 * time.Millisecond}))
+	assert.Check(t, c.IntSliceAndOr("ports", "N<8080", nil) == nil, true)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&StringSliceFlag{}, "tag", "usage")
+	fs.Var(&StringSliceFlag{}, "ports", "usage")
+	fs.Var(&StringSliceFlag{}, "empty", "usage")
+	assert.Must(t, fs.Parse([]string{"-tag", "a", "-tag", "b,c", "-ports", "1", "-ports", "2"}))
+	c.ParseFlagSet(fs)
+	assert.Check(t, fmt.Sprint(c.StringSlice("tag")), fmt.Sprint([]string{"a", "b", "c"}))
+	assert.Check(t, fmt.Sprint(c.IntSlice("ports")), fmt.Sprint([]int{1, 2}))
+	assert.Check(t, fmt.Sprint(c.StringSliceOr("empty", []string{"x"})), fmt.Sprint([]string{"x"}))
+	assert.Check(t, fs.Lookup("tag").Value.String(), "a,b,c")
+
+	var s struct {
+		Tags  []string `cc:"tag"`
+		Ports []int    `cc:"ports"`
+	}
+	assert.Must(t, c.Decode(&s))
+	assert.Check(t, fmt.Sprint(s.Tags), fmt.Sprint([]string{"a", "b", "c"}))
+	assert.Check(t, fmt.Sprint(s.Ports), fmt.Sprint([]int{1, 2}))
+}
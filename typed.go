@@ -0,0 +1,656 @@
+package cc
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// text returns the value by name from the non-empty string flag, the
+// environment variable, or the config data, in order.
+func (c *Config) text(name string) (interface{}, error) {
+	if v, ok := c.flag(name).(string); ok && v != "" {
+		return v, nil
+	}
+	if env := c.getenv(name); env != "" {
+		return env, nil
+	}
+	if v, ok := c.lookup(name); ok && v != nil {
+		return v, nil
+	}
+	return nil, fmt.Errorf("%s: not found", name)
+}
+
+// Time returns the time.Time value by name, returns the zero time if not
+// found or invalid, the layouts default to time.RFC3339.
+func (c *Config) Time(name string, layouts ...string) time.Time {
+	return c.TimeOr(name, time.Time{}, layouts...)
+}
+
+// TimeOr returns the time.Time value by name, returns the deflt if not found
+// or invalid, the layouts default to time.RFC3339.
+func (c *Config) TimeOr(name string, deflt time.Time, layouts ...string) time.Time {
+	if t, err := c.TimeE(name, layouts...); err == nil {
+		return t
+	}
+	return deflt
+}
+
+// TimeE returns the time.Time value by name, returns an error if not found or
+// not matched any of the layouts, the layouts default to time.RFC3339.
+func (c *Config) TimeE(name string, layouts ...string) (time.Time, error) {
+	v, err := c.text(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := parseTime(v, layouts)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %v", name, err)
+	}
+	return t, nil
+}
+
+// Location returns the *time.Location value by name, e.g. "Asia/Shanghai",
+// returns nil if not found or unknown.
+func (c *Config) Location(name string) *time.Location {
+	return c.LocationOr(name, nil)
+}
+
+// LocationOr returns the *time.Location value by name, returns the deflt if
+// not found or unknown.
+func (c *Config) LocationOr(name string, deflt *time.Location) *time.Location {
+	if loc, err := c.LocationE(name); err == nil {
+		return loc
+	}
+	return deflt
+}
+
+// LocationE returns the *time.Location value by name, returns an error if not
+// found or unknown.
+func (c *Config) LocationE(name string) (*time.Location, error) {
+	v, err := c.text(name)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := parseLocation(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return loc, nil
+}
+
+// URL returns the *url.URL value by name, returns nil if not found or invalid.
+func (c *Config) URL(name string) *url.URL {
+	return c.URLOr(name, nil)
+}
+
+// URLOr returns the *url.URL value by name, returns the deflt if not found or
+// invalid.
+func (c *Config) URLOr(name string, deflt *url.URL) *url.URL {
+	if u, err := c.URLE(name); err == nil {
+		return u
+	}
+	return deflt
+}
+
+// URLE returns the *url.URL value by name, returns an error if not found or
+// invalid, see url.Parse.
+func (c *Config) URLE(name string) (*url.URL, error) {
+	v, err := c.text(name)
+	if err != nil {
+		return nil, err
+	}
+	u, err := parseURL(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return u, nil
+}
+
+// IP returns the net.IP value by name, returns nil if not found or invalid.
+func (c *Config) IP(name string) net.IP {
+	return c.IPOr(name, nil)
+}
+
+// IPOr returns the net.IP value by name, returns the deflt if not found or
+// invalid.
+func (c *Config) IPOr(name string, deflt net.IP) net.IP {
+	if ip, err := c.IPE(name); err == nil {
+		return ip
+	}
+	return deflt
+}
+
+// IPE returns the net.IP value by name, returns an error if not found or
+// invalid.
+func (c *Config) IPE(name string) (net.IP, error) {
+	v, err := c.text(name)
+	if err != nil {
+		return nil, err
+	}
+	ip, err := parseIP(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return ip, nil
+}
+
+// IPNet returns the *net.IPNet value by name, e.g. "10.0.0.0/8", returns nil
+// if not found or invalid.
+func (c *Config) IPNet(name string) *net.IPNet {
+	return c.IPNetOr(name, nil)
+}
+
+// IPNetOr returns the *net.IPNet value by name, returns the deflt if not found
+// or invalid.
+func (c *Config) IPNetOr(name string, deflt *net.IPNet) *net.IPNet {
+	if ipnet, err := c.IPNetE(name); err == nil {
+		return ipnet
+	}
+	return deflt
+}
+
+// IPNetE returns the *net.IPNet value by name, returns an error if not found
+// or invalid, see net.ParseCIDR.
+func (c *Config) IPNetE(name string) (*net.IPNet, error) {
+	v, err := c.text(name)
+	if err != nil {
+		return nil, err
+	}
+	ipnet, err := parseIPNet(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return ipnet, nil
+}
+
+// Prefix returns the netip.Prefix value by name, e.g. "10.0.0.0/8", returns
+// the zero netip.Prefix if not found or invalid.
+func (c *Config) Prefix(name string) netip.Prefix {
+	return c.PrefixOr(name, netip.Prefix{})
+}
+
+// PrefixOr returns the netip.Prefix value by name, returns the deflt if not
+// found or invalid.
+func (c *Config) PrefixOr(name string, deflt netip.Prefix) netip.Prefix {
+	if prefix, err := c.PrefixE(name); err == nil {
+		return prefix
+	}
+	return deflt
+}
+
+// PrefixE returns the netip.Prefix value by name, returns an error if not
+// found or invalid, see netip.ParsePrefix.
+func (c *Config) PrefixE(name string) (netip.Prefix, error) {
+	v, err := c.text(name)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	prefix, err := parsePrefix(v)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%s: %v", name, err)
+	}
+	return prefix, nil
+}
+
+// HostPort returns the host and port by name, e.g. "localhost:8080" or
+// "[::1]:80", returns ("", 0) if not found or invalid.
+func (c *Config) HostPort(name string) (string, int) {
+	return c.HostPortOr(name, "", 0)
+}
+
+// HostPortOr returns the host and port by name, returns the (host, port) if
+// not found or invalid.
+func (c *Config) HostPortOr(name string, host string, port int) (string, int) {
+	if h, p, err := c.HostPortE(name); err == nil {
+		return h, p
+	}
+	return host, port
+}
+
+// HostPortE returns the host and port by name, returns an error if not found
+// or invalid, the host may be empty, e.g. ":8080".
+func (c *Config) HostPortE(name string) (string, int, error) {
+	v, err := c.text(name)
+	if err != nil {
+		return "", 0, err
+	}
+	host, port, err := parseHostPort(v)
+	if err != nil {
+		return "", 0, fmt.Errorf("%s: %v", name, err)
+	}
+	return host, port, nil
+}
+
+// Regexp returns the compiled *regexp.Regexp by name, returns nil if not
+// found or invalid.
+func (c *Config) Regexp(name string) *regexp.Regexp {
+	return c.RegexpOr(name, nil)
+}
+
+// RegexpOr returns the compiled *regexp.Regexp by name, returns the deflt if
+// not found or invalid.
+func (c *Config) RegexpOr(name string, deflt *regexp.Regexp) *regexp.Regexp {
+	if re, err := c.RegexpE(name); err == nil {
+		return re
+	}
+	return deflt
+}
+
+// RegexpE returns the compiled *regexp.Regexp by name, returns an error if
+// not found or invalid.
+func (c *Config) RegexpE(name string) (*regexp.Regexp, error) {
+	v, err := c.text(name)
+	if err != nil {
+		return nil, err
+	}
+	re, err := parseRegexp(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return re, nil
+}
+
+// FileMode returns the os.FileMode value by name, returns 0 if not found or
+// invalid. The strings and integers are octal digits, e.g. "0644", "0o644" or
+// 644, only the permission, setuid(4000), setgid(2000) and sticky(1000) bits
+// are allowed. NOTE: the unquoted 0644 in YAML is the integer 420, write 644
+// or "0644" instead.
+func (c *Config) FileMode(name string) os.FileMode {
+	return c.FileModeOr(name, 0)
+}
+
+// FileModeOr returns the os.FileMode value by name, returns the deflt if not
+// found or invalid.
+func (c *Config) FileModeOr(name string, deflt os.FileMode) os.FileMode {
+	if mode, err := c.FileModeE(name); err == nil {
+		return mode
+	}
+	return deflt
+}
+
+// FileModeE returns the os.FileMode value by name, returns an error if not
+// found or invalid.
+func (c *Config) FileModeE(name string) (os.FileMode, error) {
+	v, err := c.text(name)
+	if err != nil {
+		return 0, err
+	}
+	mode, err := parseFileMode(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", name, err)
+	}
+	return mode, nil
+}
+
+// Time returns the time.Time value, returns the zero time if not exists or
+// invalid, the layouts default to time.RFC3339.
+func (v *Value) Time(layouts ...string) time.Time {
+	return v.TimeOr(time.Time{}, layouts...)
+}
+
+// TimeOr returns the time.Time value, returns the deflt if not exists or
+// invalid, the layouts default to time.RFC3339.
+func (v *Value) TimeOr(deflt time.Time, layouts ...string) time.Time {
+	if t, err := v.TimeE(layouts...); err == nil {
+		return t
+	}
+	return deflt
+}
+
+// TimeE returns the time.Time value, returns an error if not exists or not
+// matched any of the layouts, the layouts default to time.RFC3339.
+func (v *Value) TimeE(layouts ...string) (time.Time, error) {
+	if !v.Exist() {
+		return time.Time{}, errNotExist
+	}
+	return parseTime(v.v, layouts)
+}
+
+// Location returns the *time.Location value, e.g. "Asia/Shanghai", returns
+// nil if not exists or unknown.
+func (v *Value) Location() *time.Location {
+	return v.LocationOr(nil)
+}
+
+// LocationOr returns the *time.Location value, returns the deflt if not
+// exists or unknown.
+func (v *Value) LocationOr(deflt *time.Location) *time.Location {
+	if loc, err := v.LocationE(); err == nil {
+		return loc
+	}
+	return deflt
+}
+
+// LocationE returns the *time.Location value, returns an error if not exists
+// or unknown.
+func (v *Value) LocationE() (*time.Location, error) {
+	if !v.Exist() {
+		return nil, errNotExist
+	}
+	return parseLocation(v.v)
+}
+
+// URL returns the *url.URL value, returns nil if not exists or invalid.
+func (v *Value) URL() *url.URL {
+	return v.URLOr(nil)
+}
+
+// URLOr returns the *url.URL value, returns the deflt if not exists or
+// invalid.
+func (v *Value) URLOr(deflt *url.URL) *url.URL {
+	if u, err := v.URLE(); err == nil {
+		return u
+	}
+	return deflt
+}
+
+// URLE returns the *url.URL value, returns an error if not exists or
+// invalid, see url.Parse.
+func (v *Value) URLE() (*url.URL, error) {
+	if !v.Exist() {
+		return nil, errNotExist
+	}
+	return parseURL(v.v)
+}
+
+// IP returns the net.IP value, returns nil if not exists or invalid.
+func (v *Value) IP() net.IP {
+	return v.IPOr(nil)
+}
+
+// IPOr returns the net.IP value, returns the deflt if not exists or invalid.
+func (v *Value) IPOr(deflt net.IP) net.IP {
+	if ip, err := v.IPE(); err == nil {
+		return ip
+	}
+	return deflt
+}
+
+// IPE returns the net.IP value, returns an error if not exists or invalid.
+func (v *Value) IPE() (net.IP, error) {
+	if !v.Exist() {
+		return nil, errNotExist
+	}
+	return parseIP(v.v)
+}
+
+// IPNet returns the *net.IPNet value, e.g. "10.0.0.0/8", returns nil if not
+// exists or invalid.
+func (v *Value) IPNet() *net.IPNet {
+	return v.IPNetOr(nil)
+}
+
+// IPNetOr returns the *net.IPNet value, returns the deflt if not exists or
+// invalid.
+func (v *Value) IPNetOr(deflt *net.IPNet) *net.IPNet {
+	if ipnet, err := v.IPNetE(); err == nil {
+		return ipnet
+	}
+	return deflt
+}
+
+// IPNetE returns the *net.IPNet value, returns an error if not exists or
+// invalid, see net.ParseCIDR.
+func (v *Value) IPNetE() (*net.IPNet, error) {
+	if !v.Exist() {
+		return nil, errNotExist
+	}
+	return parseIPNet(v.v)
+}
+
+// Prefix returns the netip.Prefix value, e.g. "10.0.0.0/8", returns the zero
+// netip.Prefix if not exists or invalid.
+func (v *Value) Prefix() netip.Prefix {
+	return v.PrefixOr(netip.Prefix{})
+}
+
+// PrefixOr returns the netip.Prefix value, returns the deflt if not exists
+// or invalid.
+func (v *Value) PrefixOr(deflt netip.Prefix) netip.Prefix {
+	if prefix, err := v.PrefixE(); err == nil {
+		return prefix
+	}
+	return deflt
+}
+
+// PrefixE returns the netip.Prefix value, returns an error if not exists or
+// invalid, see netip.ParsePrefix.
+func (v *Value) PrefixE() (netip.Prefix, error) {
+	if !v.Exist() {
+		return netip.Prefix{}, errNotExist
+	}
+	return parsePrefix(v.v)
+}
+
+// HostPort returns the host and port, e.g. "localhost:8080" or "[::1]:80",
+// returns ("", 0) if not exists or invalid.
+func (v *Value) HostPort() (string, int) {
+	return v.HostPortOr("", 0)
+}
+
+// HostPortOr returns the host and port, returns the (host, port) if not
+// exists or invalid.
+func (v *Value) HostPortOr(host string, port int) (string, int) {
+	if h, p, err := v.HostPortE(); err == nil {
+		return h, p
+	}
+	return host, port
+}
+
+// HostPortE returns the host and port, returns an error if not exists or
+// invalid, the host may be empty, e.g. ":8080".
+func (v *Value) HostPortE() (string, int, error) {
+	if !v.Exist() {
+		return "", 0, errNotExist
+	}
+	return parseHostPort(v.v)
+}
+
+// Regexp returns the compiled *regexp.Regexp, returns nil if not exists or
+// invalid.
+func (v *Value) Regexp() *regexp.Regexp {
+	return v.RegexpOr(nil)
+}
+
+// RegexpOr returns the compiled *regexp.Regexp, returns the deflt if not
+// exists or invalid.
+func (v *Value) RegexpOr(deflt *regexp.Regexp) *regexp.Regexp {
+	if re, err := v.RegexpE(); err == nil {
+		return re
+	}
+	return deflt
+}
+
+// RegexpE returns the compiled *regexp.Regexp, returns an error if not
+// exists or invalid.
+func (v *Value) RegexpE() (*regexp.Regexp, error) {
+	if !v.Exist() {
+		return nil, errNotExist
+	}
+	return parseRegexp(v.v)
+}
+
+// FileMode returns the os.FileMode value, returns 0 if not exists or invalid.
+// The strings and integers are octal digits, e.g. "0644", "0o644" or 644.
+func (v *Value) FileMode() os.FileMode {
+	return v.FileModeOr(0)
+}
+
+// FileModeOr returns the os.FileMode value, returns the deflt if not exists
+// or invalid.
+func (v *Value) FileModeOr(deflt os.FileMode) os.FileMode {
+	if mode, err := v.FileModeE(); err == nil {
+		return mode
+	}
+	return deflt
+}
+
+// FileModeE returns the os.FileMode value, returns an error if not exists
+// or invalid.
+func (v *Value) FileModeE() (os.FileMode, error) {
+	if !v.Exist() {
+		return 0, errNotExist
+	}
+	return parseFileMode(v.v)
+}
+
+func parseTime(v interface{}, layouts []string) (time.Time, error) {
+	if t, ok := v.(time.Time); ok {
+		return t, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("expected time, got %T", v)
+	}
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339}
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	quoted := make([]string, len(layouts))
+	for i, layout := range layouts {
+		quoted[i] = strconv.Quote(layout)
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected layout %s", s, strings.Join(quoted, " or "))
+}
+
+func parseLocation(v interface{}) (*time.Location, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected location, got %T", v)
+	}
+	if s == "" {
+		return nil, fmt.Errorf("empty location")
+	}
+	loc, err := time.LoadLocation(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid location %q: %v", s, err)
+	}
+	return loc, nil
+}
+
+func parseURL(v interface{}) (*url.URL, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected URL, got %T", v)
+	}
+	if s == "" {
+		return nil, fmt.Errorf("empty URL")
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %v", err)
+	}
+	return u, nil
+}
+
+func parseIP(v interface{}) (net.IP, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected IP, got %T", v)
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP %q", s)
+	}
+	return ip, nil
+}
+
+func parseIPNet(v interface{}) (*net.IPNet, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected CIDR, got %T", v)
+	}
+	_, ipnet, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q", s)
+	}
+	return ipnet, nil
+}
+
+func parsePrefix(v interface{}) (netip.Prefix, error) {
+	s, ok := v.(string)
+	if !ok {
+		return netip.Prefix{}, fmt.Errorf("expected CIDR, got %T", v)
+	}
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid CIDR %q", s)
+	}
+	return prefix, nil
+}
+
+func parseHostPort(v interface{}) (string, int, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", 0, fmt.Errorf("expected host:port, got %T", v)
+	}
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid host:port: %v", err)
+	}
+	n, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port %q in %q", port, s)
+	}
+	return host, int(n), nil
+}
+
+func parseRegexp(v interface{}) (*regexp.Regexp, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected regexp, got %T", v)
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regexp: %v", err)
+	}
+	return re, nil
+}
+
+// parseFileMode parses the octal digits of the string or integer v, the
+// setuid, setgid and sticky bits are converted into the os.FileMode ones.
+func parseFileMode(v interface{}) (os.FileMode, error) {
+	var digits string
+	switch x := v.(type) {
+	case string:
+		digits = strings.TrimPrefix(strings.TrimPrefix(x, "0o"), "0O")
+	default:
+		if !isType(v, "integer") {
+			return 0, fmt.Errorf("expected file mode, got %T", v)
+		}
+		n, ok := asUint64(v)
+		if !ok {
+			return 0, fmt.Errorf("invalid file mode %v, expected octal", v)
+		}
+		digits = strconv.FormatUint(n, 10)
+	}
+	n, err := strconv.ParseUint(digits, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid file mode %q, expected octal", fmt.Sprint(v))
+	}
+	if n&^07777 != 0 {
+		return 0, fmt.Errorf("invalid file mode %q, only the permission, setuid, setgid and sticky bits are allowed", fmt.Sprint(v))
+	}
+	mode := os.FileMode(n) & os.ModePerm
+	if n&04000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if n&02000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if n&01000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode, nil
+}
@@ -0,0 +1,152 @@
+package cc
+
+import (
+	"encoding/json"
+	"net"
+	"net/netip"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/damnever/cc/assert"
+)
+
+func TestTypedGetters(t *testing.T) {
+	c, err := NewConfigFromYAML([]byte(`
+started: 2006-01-02T15:04:05Z
+date: 2006-01-02
+tz: UTC
+endpoint: https://user@example.com:8443/api?v=1
+ip: 192.168.1.1
+ip6: "::1"
+cidr: 10.0.0.0/8
+addr: "[::1]:8080"
+listen: :80
+pattern: ^a+b$
+mode: 644
+mode_setuid: "4755"
+bad_bits: 17777
+bad_digit: 648
+mode_str: "0o755"
+num: 1
+bad_url: "http://[::1"
+bad_addr: localhost
+bad_port: localhost:http
+bad_pattern: "a("
+bad_mode: "0999"
+`))
+	assert.Must(t, err)
+
+	assert.Check(t, c.Time("started").Equal(time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)), true)
+	assert.Check(t, c.Time("date").IsZero(), true)
+	assert.Check(t, c.Time("date", time.RFC3339, "2006-01-02").Equal(time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)), true)
+	deflt := time.Unix(1, 0)
+	assert.Check(t, c.TimeOr("non", deflt), deflt)
+	_, err = c.TimeE("date", time.RFC3339, time.Kitchen)
+	assert.Check(t, err.Error(), `date: invalid time "2006-01-02", expected layout "2006-01-02T15:04:05Z07:00" or "3:04PM"`)
+	_, err = c.TimeE("num")
+	assert.Check(t, err.Error(), "num: expected time, got int")
+	_, err = c.TimeE("non")
+	assert.Check(t, err.Error(), "non: not found")
+
+	assert.Check(t, c.Location("tz"), time.UTC)
+	assert.Check(t, c.LocationOr("ip", time.Local), time.Local)
+	_, err = c.LocationE("non")
+	assert.Check(t, err != nil, true)
+
+	u := c.URL("endpoint")
+	assert.Check(t, u.Hostname(), "example.com")
+	assert.Check(t, u.Port(), "8443")
+	assert.Check(t, u.Query().Get("v"), "1")
+	assert.Check(t, c.URLOr("bad_url", u), u)
+	_, err = c.URLE("bad_url")
+	assert.Check(t, err.Error(), `bad_url: invalid URL: parse "http://[::1": missing ']' in host`)
+
+	assert.Check(t, c.IP("ip").Equal(net.IPv4(192, 168, 1, 1)), true)
+	assert.Check(t, c.IP("ip6").Equal(net.IPv6loopback), true)
+	assert.Check(t, c.IP("cidr") == nil, true)
+	_, err = c.IPE("cidr")
+	assert.Check(t, err.Error(), `cidr: invalid IP "10.0.0.0/8"`)
+
+	ipnet := c.IPNet("cidr")
+	assert.Check(t, ipnet.String(), "10.0.0.0/8")
+	assert.Check(t, ipnet.Contains(net.IPv4(10, 1, 2, 3)), true)
+	assert.Check(t, c.IPNetOr("ip", ipnet), ipnet)
+	_, err = c.IPNetE("ip")
+	assert.Check(t, err.Error(), `ip: invalid CIDR "192.168.1.1"`)
+	assert.Check(t, c.Prefix("cidr"), netip.MustParsePrefix("10.0.0.0/8"))
+	assert.Check(t, c.Prefix("ip").IsValid(), false)
+
+	host, port := c.HostPort("addr")
+	assert.Check(t, host, "::1")
+	assert.Check(t, port, 8080)
+	host, port = c.HostPort("listen")
+	assert.Check(t, host, "")
+	assert.Check(t, port, 80)
+	host, port = c.HostPortOr("bad_addr", "localhost", 80)
+	assert.Check(t, host, "localhost")
+	assert.Check(t, port, 80)
+	_, _, err = c.HostPortE("bad_addr")
+	assert.Check(t, err.Error(), "bad_addr: invalid host:port: address localhost: missing port in address")
+	_, _, err = c.HostPortE("bad_port")
+	assert.Check(t, err.Error(), `bad_port: invalid port "http" in "localhost:http"`)
+
+	assert.Check(t, c.Regexp("pattern").MatchString("aab"), true)
+	assert.Check(t, c.Regexp("bad_pattern") == nil, true)
+	_, err = c.RegexpE("bad_pattern")
+	assert.Check(t, err.Error(), "bad_pattern: invalid regexp: error parsing regexp: missing closing ): `a(`")
+
+	assert.Check(t, c.FileMode("mode"), os.FileMode(0644))
+	assert.Check(t, c.FileMode("mode_setuid"), os.ModeSetuid|0755)
+	_, err = c.FileModeE("bad_bits")
+	assert.Check(t, err.Error(), `bad_bits: invalid file mode "17777", only the permission, setuid, setgid and sticky bits are allowed`)
+	_, err = c.FileModeE("bad_digit")
+	assert.Check(t, err.Error(), `bad_digit: invalid file mode "648", expected octal`)
+	assert.Check(t, c.FileMode("mode_str"), os.FileMode(0755))
+	assert.Check(t, c.FileModeOr("bad_mode", 0600), os.FileMode(0600))
+	_, err = c.FileModeE("bad_mode")
+	assert.Check(t, err.Error(), `bad_mode: invalid file mode "0999", expected octal`)
+	_, err = c.FileModeE("ip")
+	assert.Check(t, err.Error(), `ip: invalid file mode "192.168.1.1", expected octal`)
+
+	os.Setenv("CC_TEST_ADDR", "127.0.0.1:9090")
+	defer os.Unsetenv("CC_TEST_ADDR")
+	c.BindEnv("addr", "CC_TEST_ADDR")
+	host, port = c.HostPort("addr")
+	assert.Check(t, host, "127.0.0.1")
+	assert.Check(t, port, 9090)
+}
+
+func TestValueTypedGetters(t *testing.T) {
+	assert.Check(t, NewValue("2006-01-02T15:04:05+08:00").Time().Unix(), int64(1136185445))
+	assert.Check(t, NewValue("15:04").Time("15:04").Hour(), 15)
+	assert.Check(t, NewValue(nil).TimeOr(time.Unix(1, 0)).Unix(), int64(1))
+	_, err := NewValue(nil).TimeE()
+	assert.Check(t, err, errNotExist)
+
+	assert.Check(t, NewValue("UTC").Location(), time.UTC)
+	assert.Check(t, NewValue("Nowhere/Unknown").Location() == nil, true)
+	assert.Check(t, NewValue("postgres://db:5432/app").URL().Scheme, "postgres")
+	assert.Check(t, NewValue(1).URL() == nil, true)
+	assert.Check(t, NewValue("10.0.0.1").IP().String(), "10.0.0.1")
+	assert.Check(t, NewValue("fd00::/8").IPNet().String(), "fd00::/8")
+	assert.Check(t, NewValue("fd00::/8").Prefix().Bits(), 8)
+	host, port := NewValue("example.com:443").HostPort()
+	assert.Check(t, host, "example.com")
+	assert.Check(t, port, 443)
+	_, _, err = NewValue("example.com:65536").HostPortE()
+	assert.Check(t, err.Error(), `invalid port "65536" in "example.com:65536"`)
+	assert.Check(t, NewValue("^v[0-9]+$").Regexp().MatchString("v1"), true)
+	assert.Check(t, NewValue(644).FileMode(), os.FileMode(0644))
+	assert.Check(t, NewValue(json.Number("640")).FileMode(), os.FileMode(0640))
+	assert.Check(t, NewValue(644.5).FileModeOr(0600), os.FileMode(0600))
+	assert.Check(t, NewValue("600").FileMode(), os.FileMode(0600))
+	_, err = NewValue(-1).FileModeE()
+	assert.Check(t, err.Error(), "invalid file mode -1, expected octal")
+
+	c, err := NewConfigFromYAML([]byte("servers: [\"a:1\", \"b:2\"]"))
+	assert.Must(t, err)
+	host, port = c.Value("servers").List()[1].HostPort()
+	assert.Check(t, host, "b")
+	assert.Check(t, port, 2)
+}
@@ -1,21 +1,30 @@
 package cc
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"math"
+	"strconv"
 	"time"
 
 	yaml "gopkg.in/yaml.v2"
 )
 
-func unmarshalYAMLFile(fpath string) (map[string]interface{}, error) {
-	content, err := ioutil.ReadFile(fpath)
-	if err != nil {
-		return nil, err
-	}
-	return unmarshalYAML(content)
+// unmarshalers are the unmarshal functions of the supported file extensions.
+var unmarshalers = map[string]func(b []byte) (map[string]interface{}, error){
+	".yaml": unmarshalYAML,
+	".yml":  unmarshalYAML,
+	".json": unmarshalJSON,
+	".env":  unmarshalDotenv,
+
+	".ini":        unmarshalINI,
+	".properties": unmarshalProperties,
+	".hcl":        unmarshalHCL,
+	".jsonc":      unmarshalJSON5,
+	".json5":      unmarshalJSON5,
 }
 
 func unmarshalYAML(b []byte) (map[string]interface{}, error) {
@@ -34,48 +43,82 @@ func unknownMapToStringMap(data map[interface{}]interface{}) map[string]interfac
 	return kv
 }
 
-func unmarshalJSONFile(fpath string) (map[string]interface{}, error) {
-	content, err := ioutil.ReadFile(fpath)
-	if err != nil {
-		return nil, err
-	}
-	return unmarshalJSON(content)
-}
-
+// unmarshalJSON unmarshals the numbers as json.Number to keep the precision.
 func unmarshalJSON(b []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
 	var data map[string]interface{}
-	if err := json.Unmarshal(b, &data); err != nil {
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid character after top-level value")
+	}
+	if err := checkJSONNumbers(data, ""); err != nil {
 		return nil, err
 	}
 	return data, nil
 }
 
+// checkJSONNumbers checks the json.Numbers in v recursively, returns an error
+// if any of them overflows float64, e.g. 1e400.
+func checkJSONNumbers(v interface{}, path string) error {
+	switch x := v.(type) {
+	case json.Number:
+		if _, err := strconv.ParseFloat(string(x), 64); err != nil {
+			return fmt.Errorf("%s: number %s overflows float64", path, x)
+		}
+	case map[string]interface{}:
+		for k, e := range x {
+			if err := checkJSONNumbers(e, joinPath(path, k)); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, e := range x {
+			if err := checkJSONNumbers(e, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func parseFlags() map[string]interface{} {
 	if !flag.Parsed() {
 		flag.Parse()
 	}
+	return parseFlagSet(flag.CommandLine)
+}
 
+func parseFlagSet(fs *flag.FlagSet) map[string]interface{} {
 	kv := map[string]interface{}{}
 
-	flag.VisitAll(func(f *flag.Flag) {
-		v := f.Value.(flag.Getter).Get()
+	fs.VisitAll(func(f *flag.Flag) {
+		getter, ok := f.Value.(flag.Getter)
+		if !ok {
+			return
+		}
+		v := getter.Get()
 		switch x := v.(type) {
 		case string:
 			kv[f.Name] = x
 		case bool:
 			kv[f.Name] = x
 		case uint:
-			kv[f.Name] = int64(x)
+			kv[f.Name] = x
 		case int:
 			kv[f.Name] = x
-		case uint64: // XXX: may overflow, ignore?
-			kv[f.Name] = int64(x)
+		case uint64:
+			kv[f.Name] = x
 		case int64:
 			kv[f.Name] = x
 		case time.Duration:
 			kv[f.Name] = int64(x)
 		case float64:
 			kv[f.Name] = x
+		case []string:
+			kv[f.Name] = x
 		}
 	})
 	return kv
@@ -96,57 +139,202 @@ func toString(v interface{}, deflt string) string {
 }
 
 func toInt(v interface{}, deflt int) int {
-	switch x := v.(type) {
-	case int:
-		return x
-	case int32:
-		return int(x)
-	case float32:
-		return int(x)
-	case float64: // for JSON
-		return int(x)
-	case int16:
-		return int(x)
-	case int8:
-		return int(x)
+	if n, ok := asInt64(v); ok && int64(int(n)) == n {
+		return int(n)
 	}
 	return deflt
 }
 
 func toInt64(v interface{}, deflt int64) int64 {
+	if n, ok := asInt64(v); ok {
+		return n
+	}
+	return deflt
+}
+
+func toFloat64(v interface{}, deflt float64) float64 {
+	if n, ok := asFloat64(v); ok {
+		return n
+	}
+	return deflt
+}
+
+func toUint(v interface{}, deflt uint) uint {
+	if n, ok := asUint64(v); ok && n <= math.MaxUint {
+		return uint(n)
+	}
+	return deflt
+}
+
+func toUint64(v interface{}, deflt uint64) uint64 {
+	if n, ok := asUint64(v); ok {
+		return n
+	}
+	return deflt
+}
+
+func toInt32(v interface{}, deflt int32) int32 {
+	if n, ok := asInt64(v); ok && n >= math.MinInt32 && n <= math.MaxInt32 {
+		return int32(n)
+	}
+	return deflt
+}
+
+func toFloat32(v interface{}, deflt float32) float32 {
+	if n, ok := asFloat64(v); ok && !overflowsFloat32(n) {
+		return float32(n)
+	}
+	return deflt
+}
+
+// toUint64E converts the number v into uint64 which is at most max,
+// typ is the name of the target type for the error.
+func toUint64E(v interface{}, max uint64, typ string) (uint64, error) {
+	n, ok := asUint64(v)
+	if !ok {
+		if _, isNum := asFloat64(v); !isNum {
+			return 0, fmt.Errorf("expected %s, got %T", typ, v)
+		}
+	}
+	if !ok || n > max {
+		return 0, fmt.Errorf("%v overflows %s", v, typ)
+	}
+	return n, nil
+}
+
+// toInt64E converts the number v into int64 which is in [min, max],
+// typ is the name of the target type for the error.
+func toInt64E(v interface{}, min, max int64, typ string) (int64, error) {
+	n, ok := asInt64(v)
+	if !ok {
+		if _, isNum := asFloat64(v); !isNum {
+			return 0, fmt.Errorf("expected %s, got %T", typ, v)
+		}
+	}
+	if !ok || n < min || n > max {
+		return 0, fmt.Errorf("%v overflows %s", v, typ)
+	}
+	return n, nil
+}
+
+func toFloat32E(v interface{}) (float32, error) {
+	n, ok := asFloat64(v)
+	if !ok {
+		return 0, fmt.Errorf("expected float32, got %T", v)
+	}
+	if overflowsFloat32(n) {
+		return 0, fmt.Errorf("%v overflows float32", v)
+	}
+	return float32(n), nil
+}
+
+// overflowsFloat32 reports whether the finite n overflows float32.
+func overflowsFloat32(n float64) bool {
+	return !math.IsInf(n, 0) && math.Abs(n) > math.MaxFloat32
+}
+
+// asInt64 converts the number v into int64, the integers are converted
+// exactly and the floats are truncated, returns false if v is not a number
+// or overflows int64.
+func asInt64(v interface{}) (int64, bool) {
 	switch x := v.(type) {
-	case int64:
-		return x
 	case int:
-		return int64(x)
+		return int64(x), true
+	case int64:
+		return x, true
 	case int32:
-		return int64(x)
-	case float64:
-		return int64(x)
-	case float32:
-		return int64(x)
+		return int64(x), true
 	case int16:
-		return int64(x)
+		return int64(x), true
 	case int8:
-		return int64(x)
+		return int64(x), true
+	case uint:
+		return int64(x), uint64(x) <= math.MaxInt64
+	case uint64:
+		return int64(x), x <= math.MaxInt64
+	case uint32:
+		return int64(x), true
+	case uint16:
+		return int64(x), true
+	case uint8:
+		return int64(x), true
+	case float64: // for JSON
+		return floatToInt64(x)
+	case float32:
+		return floatToInt64(float64(x))
+	case json.Number:
+		if n, err := x.Int64(); err == nil {
+			return n, true
+		}
+		if f, err := x.Float64(); err == nil {
+			return floatToInt64(f)
+		}
 	}
-	return deflt
+	return 0, false
 }
 
-func toFloat64(v interface{}, deflt float64) float64 {
+// asUint64 converts the number v into uint64 as asInt64, returns false if v
+// is not a number, is negative or overflows uint64.
+func asUint64(v interface{}) (uint64, bool) {
+	switch x := v.(type) {
+	case uint:
+		return uint64(x), true
+	case uint64:
+		return x, true
+	case json.Number:
+		if n, err := strconv.ParseUint(string(x), 10, 64); err == nil {
+			return n, true
+		}
+		if f, err := x.Float64(); err == nil && f >= 0 && f < 1<<64 {
+			return uint64(f), true
+		}
+		return 0, false
+	}
+	if n, ok := asInt64(v); ok && n >= 0 {
+		return uint64(n), true
+	}
+	return 0, false
+}
+
+// asFloat64 converts the number v into float64, returns false if v is not
+// a number.
+func asFloat64(v interface{}) (float64, bool) {
 	switch x := v.(type) {
 	case float64:
-		return x
+		return x, true
 	case float32:
-		return float64(x)
+		return float64(x), true
 	case int:
-		return float64(x)
+		return float64(x), true
+	case int64:
+		return float64(x), true
 	case int32:
-		return float64(x)
+		return float64(x), true
 	case int16:
-		return float64(x)
+		return float64(x), true
 	case int8:
-		return float64(x)
+		return float64(x), true
+	case uint:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	case uint32:
+		return float64(x), true
+	case uint16:
+		return float64(x), true
+	case uint8:
+		return float64(x), true
+	case json.Number:
+		f, err := x.Float64()
+		return f, err == nil
 	}
-	return deflt
+	return 0, false
+}
+
+// floatToInt64 truncates f, returns false if f is NaN or overflows int64.
+func floatToInt64(f float64) (int64, bool) {
+	if f != f || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
 }
@@ -1,16 +1,21 @@
 package cc
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"time"
 )
 
 // TODO(damnever): cache used value
 
+var errNotExist = errors.New("value not exists")
+
 // Value implements the Valuer interface.
 type Value struct {
-	v interface{}
+	v        interface{}
+	coercion Coercion
 }
 
 // NewValue creates a new Value.
@@ -18,6 +23,11 @@ func NewValue(v interface{}) *Value {
 	return &Value{v: v}
 }
 
+// child creates a Value from the element of v with the same coercion policy.
+func (v *Value) child(e interface{}) *Value {
+	return &Value{v: e, coercion: v.coercion}
+}
+
 // Exist returns true is value is a valid value, otherwise false.
 func (v *Value) Exist() bool {
 	if v.v == nil {
@@ -26,8 +36,13 @@ func (v *Value) Exist() bool {
 	return reflect.ValueOf(v.v).IsValid()
 }
 
-// Pattern returns a Patterner.
+// Pattern returns a Patterner, if the value is a list,
+// all the patterns in it must be valid, see All.
 func (v *Value) Pattern() Patterner {
+	switch x := v.v.(type) {
+	case []interface{}, map[string]interface{}, map[interface{}]interface{}:
+		return newPatternFrom(x)
+	}
 	return NewPattern(v.String())
 }
 
@@ -39,25 +54,21 @@ func (v *Value) Raw() interface{} {
 // Config returns the value as a Configer, the modification on returned
 // Configer has no affect to the origin value.
 func (v *Value) Config() Configer {
+	val := newConfig()
+	val.coercion = v.coercion
 	switch x := v.v.(type) {
 	case Configer:
-		val := newConfig()
 		for kx, kv := range x.KV() {
 			val.kv[kx] = kv
 		}
-		return val
 	case map[string]interface{}:
-		val := newConfig()
 		for kx, kv := range x {
 			val.kv[kx] = kv
 		}
-		return val
 	case map[interface{}]interface{}:
-		val := newConfig()
 		val.kv = unknownMapToStringMap(x)
-		return val
 	}
-	return newConfig()
+	return val
 }
 
 // Map returns the value as a map, the modification on returned
@@ -68,19 +79,19 @@ func (v *Value) Map() map[string]Valuer {
 		val := x.KV()
 		ms := make(map[string]Valuer, len(val))
 		for kx, vx := range val {
-			ms[kx] = NewValue(vx)
+			ms[kx] = v.child(vx)
 		}
 		return ms
 	case map[string]interface{}:
 		ms := make(map[string]Valuer, len(x))
 		for kx, vx := range x {
-			ms[kx] = NewValue(vx)
+			ms[kx] = v.child(vx)
 		}
 		return ms
 	case map[interface{}]interface{}:
 		ms := make(map[string]Valuer, len(x))
 		for kx, kv := range x {
-			ms[fmt.Sprintf("%v", kx)] = NewValue(kv)
+			ms[fmt.Sprintf("%v", kx)] = v.child(kv)
 		}
 		return ms
 	}
@@ -93,7 +104,7 @@ func (v *Value) List() []Valuer {
 	if x, ok := v.v.([]interface{}); ok {
 		vs := make([]Valuer, len(x))
 		for i, e := range x {
-			vs[i] = NewValue(e)
+			vs[i] = v.child(e)
 		}
 		return vs
 	}
@@ -105,9 +116,10 @@ func (v *Value) String() string {
 	return v.StringOr("")
 }
 
-// StringOr returns the string value, returns the deflt if not exists.
+// StringOr returns the string value, returns the deflt if not exists. The
+// Secret is not revealed since String implements the fmt.Stringer, use Secret.
 func (v *Value) StringOr(deflt string) string {
-	return toString(v.v, deflt)
+	return toString(v.coercion.text(v.v), deflt)
 }
 
 // StringAnd returns the (string value, true) if pattern matched,
@@ -139,7 +151,7 @@ func (v *Value) Bool() bool {
 
 // BoolOr returns the bool value, returns the deflt if not exists.
 func (v *Value) BoolOr(deflt bool) bool {
-	return toBool(v.v, deflt)
+	return toBool(v.coercion.boolean(v.v), deflt)
 }
 
 // Int returns the int value, returns 0 if not exists.
@@ -149,7 +161,7 @@ func (v *Value) Int() int {
 
 // IntOr returns the int value, returns the deflt if not exists.
 func (v *Value) IntOr(deflt int) int {
-	return toInt(v.v, deflt)
+	return toInt(v.coercion.numeric(v.v), deflt)
 }
 
 // IntAnd returns the (int value, true) if pattern matched,
@@ -181,7 +193,7 @@ func (v *Value) Int64() int64 {
 
 // Int64Or returns the int64 value, returns the deflt if not exists.
 func (v *Value) Int64Or(deflt int64) int64 {
-	return toInt64(v.v, deflt)
+	return toInt64(v.coercion.numeric(v.v), deflt)
 }
 
 // Int64And returns the (int64 value, true) if pattern matched,
@@ -215,7 +227,7 @@ func (v *Value) Float() float64 {
 
 // FloatOr returns the float64 value, return the deflt if not exists.
 func (v *Value) FloatOr(deflt float64) float64 {
-	return toFloat64(v.v, deflt)
+	return toFloat64(v.coercion.numeric(v.v), deflt)
 }
 
 // FloatAnd returns the (float64 value, true) if pattern matched,
@@ -240,6 +252,176 @@ func (v *Value) FloatAndOr(pattern string, deflt float64) float64 {
 	return deflt
 }
 
+// Uint returns the uint value, returns 0 if not exists.
+func (v *Value) Uint() uint {
+	return v.UintOr(0)
+}
+
+// UintOr returns the uint value, returns the deflt if not exists or overflows.
+func (v *Value) UintOr(deflt uint) uint {
+	return toUint(v.coercion.numeric(v.v), deflt)
+}
+
+// UintE returns the uint value, returns an error if not exists, not a
+// number or overflows.
+func (v *Value) UintE() (uint, error) {
+	if !v.Exist() {
+		return 0, errNotExist
+	}
+	n, err := toUint64E(v.coercion.numeric(v.v), math.MaxUint, "uint")
+	return uint(n), err
+}
+
+// UintAnd returns the (uint value, true) if pattern matched,
+// otherwise returns (0, false). NOTE: we convert all numbers into
+// float64 then validate.
+func (v *Value) UintAnd(pattern string) (uint, bool) {
+	n, err := v.UintE()
+	if err != nil {
+		return 0, false
+	}
+	if p := NewPattern(pattern); p.ValidateFloat(float64(n)) {
+		return n, true
+	}
+	return 0, false
+}
+
+// UintAndOr returns the uint value if pattern matched,
+// otherwise returns the deflt. NOTE: we convert all numbers into
+// float64 then validate.
+func (v *Value) UintAndOr(pattern string, deflt uint) uint {
+	if n, ok := v.UintAnd(pattern); ok {
+		return n
+	}
+	return deflt
+}
+
+// Uint64 returns the uint64 value, returns 0 if not exists.
+func (v *Value) Uint64() uint64 {
+	return v.Uint64Or(0)
+}
+
+// Uint64Or returns the uint64 value, returns the deflt if not exists or overflows.
+func (v *Value) Uint64Or(deflt uint64) uint64 {
+	return toUint64(v.coercion.numeric(v.v), deflt)
+}
+
+// Uint64E returns the uint64 value, returns an error if not exists, not a
+// number or overflows.
+func (v *Value) Uint64E() (uint64, error) {
+	if !v.Exist() {
+		return 0, errNotExist
+	}
+	return toUint64E(v.coercion.numeric(v.v), math.MaxUint64, "uint64")
+}
+
+// Uint64And returns the (uint64 value, true) if pattern matched,
+// otherwise returns (0, false). NOTE: we convert all numbers into
+// float64 then validate.
+func (v *Value) Uint64And(pattern string) (uint64, bool) {
+	n, err := v.Uint64E()
+	if err != nil {
+		return 0, false
+	}
+	if p := NewPattern(pattern); p.ValidateFloat(float64(n)) {
+		return n, true
+	}
+	return 0, false
+}
+
+// Uint64AndOr returns the uint64 value if pattern matched,
+// otherwise returns the deflt. NOTE: we convert all numbers into
+// float64 then validate.
+func (v *Value) Uint64AndOr(pattern string, deflt uint64) uint64 {
+	if n, ok := v.Uint64And(pattern); ok {
+		return n
+	}
+	return deflt
+}
+
+// Int32 returns the int32 value, returns 0 if not exists.
+func (v *Value) Int32() int32 {
+	return v.Int32Or(0)
+}
+
+// Int32Or returns the int32 value, returns the deflt if not exists or overflows.
+func (v *Value) Int32Or(deflt int32) int32 {
+	return toInt32(v.coercion.numeric(v.v), deflt)
+}
+
+// Int32E returns the int32 value, returns an error if not exists, not a
+// number or overflows.
+func (v *Value) Int32E() (int32, error) {
+	if !v.Exist() {
+		return 0, errNotExist
+	}
+	n, err := toInt64E(v.coercion.numeric(v.v), math.MinInt32, math.MaxInt32, "int32")
+	return int32(n), err
+}
+
+// Int32And returns the (int32 value, true) if pattern matched,
+// otherwise returns (0, false).
+func (v *Value) Int32And(pattern string) (int32, bool) {
+	n, err := v.Int32E()
+	if err != nil {
+		return 0, false
+	}
+	if p := NewPattern(pattern); p.ValidateInt(int(n)) {
+		return n, true
+	}
+	return 0, false
+}
+
+// Int32AndOr returns the int32 value if pattern matched,
+// otherwise returns the deflt.
+func (v *Value) Int32AndOr(pattern string, deflt int32) int32 {
+	if n, ok := v.Int32And(pattern); ok {
+		return n
+	}
+	return deflt
+}
+
+// Float32 returns the float32 value, returns 0.0 if not exists.
+func (v *Value) Float32() float32 {
+	return v.Float32Or(0.0)
+}
+
+// Float32Or returns the float32 value, returns the deflt if not exists or overflows.
+func (v *Value) Float32Or(deflt float32) float32 {
+	return toFloat32(v.coercion.numeric(v.v), deflt)
+}
+
+// Float32E returns the float32 value, returns an error if not exists, not a
+// number or overflows.
+func (v *Value) Float32E() (float32, error) {
+	if !v.Exist() {
+		return 0, errNotExist
+	}
+	return toFloat32E(v.coercion.numeric(v.v))
+}
+
+// Float32And returns the (float32 value, true) if pattern matched,
+// otherwise returns (0.0, false).
+func (v *Value) Float32And(pattern string) (float32, bool) {
+	n, err := v.Float32E()
+	if err != nil {
+		return 0.0, false
+	}
+	if p := NewPattern(pattern); p.ValidateFloat(float64(n)) {
+		return n, true
+	}
+	return 0.0, false
+}
+
+// Float32AndOr returns the float32 value if pattern matched,
+// otherwise returns the deflt.
+func (v *Value) Float32AndOr(pattern string, deflt float32) float32 {
+	if n, ok := v.Float32And(pattern); ok {
+		return n
+	}
+	return deflt
+}
+
 // Duration returns the time.Duration value, returns time.Duration(0) if not exists.
 func (v *Value) Duration() time.Duration {
 	return v.DurationOr(0)
@@ -248,6 +430,9 @@ func (v *Value) Duration() time.Duration {
 // DurationOr returns the time.Duration value, returns time.Duration(deflt)
 // if not exists.
 func (v *Value) DurationOr(deflt int64) time.Duration {
+	if d, ok := v.coercion.duration(v.v); ok {
+		return d
+	}
 	return time.Duration(v.Int64Or(deflt))
 }
 
@@ -255,8 +440,14 @@ func (v *Value) DurationOr(deflt int64) time.Duration {
 // otherwise (time.Duration(0), false) returned. NOTE: we convert all numbers
 // into float64 then validate.
 func (v *Value) DurationAnd(pattern string) (time.Duration, bool) {
-	n, ok := v.Int64And(pattern)
-	return time.Duration(n), ok
+	if !v.Exist() {
+		return 0, false
+	}
+	p := NewPattern(pattern)
+	if d := v.Duration(); p.ValidateFloat(float64(d)) {
+		return d, true
+	}
+	return 0, false
 }
 
 // DurationAndOr returns the time.Duration value if pattern matched,
@@ -269,7 +460,8 @@ func (v *Value) DurationAndOr(pattern string, deflt int64) time.Duration {
 	return time.Duration(deflt)
 }
 
-// GoString implements the native format for Value
+// GoString implements the native format for Value, the Secrets in it are
+// redacted.
 func (v *Value) GoString() string {
 	return fmt.Sprintf("%v", v.v)
 }
@@ -1,7 +1,9 @@
 package cc
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -151,6 +153,54 @@ func TestValueToFloat(t *testing.T) {
 	assert.Check(t, v.FloatOr(0.5), 0.5)
 }
 
+func TestValueToUint(t *testing.T) {
+	for _, raw := range []interface{}{7, int64(7), uint64(7), 7.0, json.Number("7")} {
+		v := NewValue(raw)
+		assert.Check(t, v.Uint(), uint(7))
+		assert.Check(t, v.Uint64(), uint64(7))
+		assert.Check(t, v.Int32(), int32(7))
+		assert.Check(t, v.Float32(), float32(7))
+	}
+
+	v := NewValue(uint64(math.MaxUint64))
+	assert.Check(t, v.Uint64(), uint64(math.MaxUint64))
+	assert.Check(t, v.Int64Or(-1), int64(-1))
+	assert.Check(t, v.Int32Or(-1), int32(-1))
+	res, ok := v.Uint64And("N>0")
+	assert.Check(t, ok, true)
+	assert.Check(t, res, uint64(math.MaxUint64))
+	assert.Check(t, v.Uint64AndOr("N<0", 1), uint64(1))
+
+	v = NewValue(-1)
+	assert.Check(t, v.UintOr(3), uint(3))
+	_, err := v.UintE()
+	assert.Check(t, err.Error(), "-1 overflows uint")
+	_, ok = v.UintAnd("N<0")
+	assert.Check(t, ok, false)
+	assert.Check(t, v.UintAndOr("N<0", 3), uint(3))
+
+	v = NewValue(json.Number("2147483648"))
+	_, err = v.Int32E()
+	assert.Check(t, err.Error(), "2147483648 overflows int32")
+	assert.Check(t, v.Int32Or(1), int32(1))
+	n, ok := v.Int32And("N>0")
+	assert.Check(t, ok, false)
+	assert.Check(t, n, int32(0))
+	assert.Check(t, NewValue(-3).Int32AndOr("N<0", 1), int32(-3))
+
+	v = NewValue(1e300)
+	_, err = v.Float32E()
+	assert.Check(t, err.Error(), "1e+300 overflows float32")
+	assert.Check(t, v.Float32Or(1.5), float32(1.5))
+	assert.Check(t, NewValue(2.5).Float32AndOr("N>2", 1), float32(2.5))
+	assert.Check(t, NewValue(2.5).Float32AndOr("N>3", 1), float32(1))
+
+	_, err = NewValue("x").Uint64E()
+	assert.Check(t, err.Error(), "expected uint64, got string")
+	_, err = NewValue(nil).Float32E()
+	assert.Check(t, err, errNotExist)
+}
+
 func TestValueToDuration(t *testing.T) {
 	v := NewValue(23)
 	assert.Check(t, v.Duration(), time.Duration(23))